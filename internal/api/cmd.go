@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+type Command struct {
+	printStandard bool
+
+	output  string
+	noAlign bool
+}
+
+func (*Command) Name() string     { return "api" }
+func (*Command) Synopsis() string { return "Summarize exported declarations and their documentation." }
+func (*Command) Usage() string {
+	return `api <expr>:
+	Count the exported declarations of packages, how many of them lack
+	a doc comment and how many are deprecated.
+
+	Columns:
+	  Exported    Exported funcs, methods, types, consts and vars.
+	  Method      Exported methods of exported types.
+	  Undoc       Exported declarations without a doc comment. The doc
+	              comment of a const, var or type group documents the
+	              specs in it.
+	  Doc%        Percentage of exported declarations with a doc comment.
+	  Deprecated  Declarations with a "Deprecated:" paragraph.
+
+	Main packages and packages without exported declarations are skipped.
+	The same numbers are available as .Stat.API in "list" templates,
+	see "help format".
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json)")
+	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected text or json\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.From(result)
+	report := ReportOf(graph.Sorted)
+
+	if output == "json" {
+		err = WriteJSON(os.Stdout, report)
+	} else {
+		err = WriteText(os.Stdout, report, cmd.noAlign)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
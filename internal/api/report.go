@@ -0,0 +1,80 @@
+// Package api summarizes the exported declarations of packages.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// Package is the API of a single package.
+type Package struct {
+	ID         string
+	API        stat.API
+	Documented float64
+}
+
+// Report is the API of packages and their total.
+type Report struct {
+	Packages []Package
+	Total    Package
+}
+
+// ReportOf returns the API of the nodes, skipping the ones without
+// exported declarations.
+func ReportOf(nodes []*pkggraph.Node) Report {
+	report := Report{Packages: []Package{}}
+	var total stat.API
+	for _, n := range nodes {
+		api := n.Stat.API
+		if api.Total() == 0 {
+			continue
+		}
+		total.Add(api)
+		report.Packages = append(report.Packages, Package{
+			ID:         n.ID,
+			API:        api,
+			Documented: api.Documented(),
+		})
+	}
+	report.Total = Package{ID: "total", API: total, Documented: total.Documented()}
+	return report
+}
+
+// WriteText writes the report as a table with a total row.
+func WriteText(out io.Writer, report Report, noAlign bool) error {
+	var w io.Writer = out
+	if !noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintln(w, "ID\tExported\tFunc\tMethod\tType\tConst\tVar\tUndoc\tDoc%\tDeprecated")
+	row := func(p Package) {
+		api := p.API
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f\t%d\n", p.ID,
+			api.Total(), api.Func, api.Method, api.Type, api.Const, api.Var,
+			api.Undocumented, p.Documented, api.Deprecated)
+	}
+	for _, p := range report.Packages {
+		row(p)
+	}
+	if len(report.Packages) > 1 {
+		row(report.Total)
+	}
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+	return nil
+}
+
+// WriteJSON writes the report as a JSON object.
+func WriteJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(report)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+func TestReport(t *testing.T) {
+	node := func(id string, api stat.API) *pkggraph.Node {
+		n := &pkggraph.Node{Package: &packages.Package{ID: id}}
+		n.Stat.API = api
+		return n
+	}
+	report := ReportOf([]*pkggraph.Node{
+		node("a", stat.API{Func: 2, Type: 1, Method: 1, Undocumented: 1}),
+		node("cmd", stat.API{}),
+		node("b", stat.API{Const: 3, Var: 1, Undocumented: 3, Deprecated: 1}),
+	})
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, report, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "ID\tExported\tFunc\tMethod\tType\tConst\tVar\tUndoc\tDoc%\tDeprecated\n" +
+		"a\t4\t2\t1\t1\t0\t0\t1\t75.0\t0\n" +
+		"b\t4\t0\t0\t0\t3\t1\t3\t25.0\t1\n" +
+		"total\t8\t2\t1\t1\t3\t1\t4\t50.0\t1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// Violation is an import that breaks a rule.
+type Violation struct {
+	Rule *Rule
+	// From is the importing package and To the imported package.
+	From, To string
+	// Import is the import spec in From. Its File is empty when the
+	// spec could not be found, e.g. for cgo.
+	Import stat.Import
+}
+
+// Resolver computes the packages matching an expression.
+type Resolver func(expr string) (pkgset.Set, error)
+
+// CalcResolver returns a resolver evaluating expressions with pkgset.Calc
+// in dir, where each distinct expression is evaluated once.
+func CalcResolver(ctx context.Context, dir string) Resolver {
+	cache := map[string]pkgset.Set{}
+	return func(expr string) (pkgset.Set, error) {
+		if set, ok := cache[expr]; ok {
+			return set, nil
+		}
+		set, err := pkgset.CalcWithOpts(ctx, []string{expr}, pkgset.CalcOpts{Dir: dir})
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", expr, err)
+		}
+		cache[expr] = set
+		return set, nil
+	}
+}
+
+// matcher reports whether the import from -> to breaks a rule.
+type matcher func(from, to string) bool
+
+// compile resolves the expressions of rule.
+func (rule *Rule) compile(resolve Resolver) (matcher, error) {
+	if rule.Kind == Layers {
+		layer := map[string]int{}
+		// The first layer wins for packages in several layers.
+		for i := len(rule.Layers) - 1; i >= 0; i-- {
+			set, err := resolve(rule.Layers[i])
+			if err != nil {
+				return nil, err
+			}
+			for id := range set {
+				layer[id] = i
+			}
+		}
+		return func(from, to string) bool {
+			a, ok := layer[from]
+			b, ok2 := layer[to]
+			return ok && ok2 && a > b
+		}, nil
+	}
+
+	fromSet, err := resolve(rule.From)
+	if err != nil {
+		return nil, err
+	}
+	toSet, err := resolve(rule.To)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Kind == AllowOnly {
+		// Imports within the protected packages are always allowed.
+		return func(from, to string) bool {
+			_, allowed := fromSet[from]
+			_, internal := toSet[from]
+			_, target := toSet[to]
+			return target && !allowed && !internal
+		}, nil
+	}
+	return func(from, to string) bool {
+		_, source := fromSet[from]
+		_, target := toSet[to]
+		return source && target
+	}, nil
+}
+
+// Check returns the imports of the packages in graph breaking any of the rules,
+// sorted by importing package and position.
+func Check(graph *pkggraph.Graph, rules []*Rule, resolve Resolver) ([]Violation, error) {
+	matchers := make([]matcher, len(rules))
+	for i, rule := range rules {
+		var err error
+		matchers[i], err = rule.compile(resolve)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", rule.Line, err)
+		}
+	}
+
+	var violations []Violation
+	for _, n := range graph.Sorted {
+		p := n.Package
+		paths := make([]string, 0, len(p.Imports))
+		for path := range p.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			imported := p.Imports[path]
+			if imported.ID == p.ID {
+				continue
+			}
+			for i, rule := range rules {
+				if !matchers[i](p.ID, imported.ID) {
+					continue
+				}
+				found := false
+				for _, spec := range n.ImportSpecs {
+					if spec.Path == path {
+						violations = append(violations, Violation{Rule: rule, From: p.ID, To: imported.ID, Import: spec})
+						found = true
+					}
+				}
+				if !found {
+					violations = append(violations, Violation{Rule: rule, From: p.ID, To: imported.ID, Import: stat.Import{Path: path}})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, k int) bool {
+		a, b := &violations[i], &violations[k]
+		switch {
+		case a.From != b.From:
+			return a.From < b.From
+		case a.Import.File != b.Import.File:
+			return a.Import.File < b.Import.File
+		default:
+			return a.Import.Line < b.Import.Line
+		}
+	})
+	return violations, nil
+}
@@ -0,0 +1,72 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+func TestCheck(t *testing.T) {
+	db := &packages.Package{ID: "wiring/db", PkgPath: "wiring/db", Imports: map[string]*packages.Package{}}
+	wiring := &packages.Package{ID: "wiring", PkgPath: "wiring", Imports: map[string]*packages.Package{"wiring/db": db}}
+	domain := &packages.Package{ID: "domain", PkgPath: "domain", Imports: map[string]*packages.Package{}}
+	app := &packages.Package{ID: "app", PkgPath: "app", Imports: map[string]*packages.Package{"domain": domain, "wiring": wiring}}
+	infra := &packages.Package{ID: "infra", PkgPath: "infra", Imports: map[string]*packages.Package{"domain": domain, "app": app}}
+	cmd := &packages.Package{ID: "cmd", PkgPath: "cmd", Imports: map[string]*packages.Package{"app": app, "wiring": wiring}}
+	domain.Imports["infra"] = infra
+	scope := pkgset.Set{"cmd": cmd, "app": app, "domain": domain, "infra": infra, "wiring": wiring, "wiring/db": db}
+	graph := pkggraph.From(scope)
+	graph.Packages["app"].ImportSpecs = []stat.Import{
+		{Path: "domain", File: "app/app.go", Line: 3},
+		{Path: "wiring", File: "app/app.go", Line: 4},
+	}
+
+	resolve := func(expr string) (pkgset.Set, error) {
+		set := pkgset.Set{}
+		for id := range strings.FieldsSeq(expr) {
+			p, ok := scope[id]
+			if !ok {
+				return nil, fmt.Errorf("unknown package %q", id)
+			}
+			set[id] = p
+		}
+		return set, nil
+	}
+
+	rules, err := ParseRules(strings.NewReader(`
+deny: domain -> infra
+allow only: cmd -> wiring wiring/db
+layers: [cmd, app, domain infra]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := Check(graph, rules, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, fmt.Sprintf("%s:%d: %s->%s %s", v.Import.File, v.Import.Line, v.From, v.To, v.Rule.Kind))
+	}
+	want := []string{
+		"app/app.go:4: app->wiring allow only",
+		":0: domain->infra deny",
+		":0: infra->app layers",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	rules[0].From = "missing"
+	if _, err := Check(graph, rules, resolve); err == nil {
+		t.Error("expected an error for an unknown package")
+	}
+}
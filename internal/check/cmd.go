@@ -0,0 +1,104 @@
+package check
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+	"github.com/flamingoosesoftwareinc/goda/internal/sarif"
+)
+
+type Command struct {
+	rulesFile string
+	output    string
+}
+
+func (*Command) Name() string     { return "check" }
+func (*Command) Synopsis() string { return "Check imports against architecture rules." }
+func (*Command) Usage() string {
+	return `check -rules goda.rules <expr>:
+	Check the imports of the packages in expr, ./... by default,
+	against dependency rules and report each violating import with
+	its file and line. Exits with a non-zero status on violations.
+
+	The rules file contains one rule per line:
+
+	  # comment
+	  deny: ./domain/... -> ./infra/...
+	  allow only: ./cmd/... -> ./internal/wiring
+	  layers: [./cmd/..., ./internal/app/..., ./domain/...]
+
+	  deny: A -> B        packages in A must not import packages in B.
+	  allow only: A -> B  only packages in A may import packages in B,
+	                      besides the packages in B themselves.
+	  layers: [A, B, C]   packages may import packages in their own
+	                      and lower layers, but not in higher ones.
+
+	The packages are package expressions, evaluated in the current
+	directory. See "help expr" for further information about expressions.
+
+	-o sarif writes a SARIF 2.1.0 log for code scanning, with the files
+	relative to the root of the git repository.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.rulesFile, "rules", "goda.rules", "file with dependency rules")
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json, sarif)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" && output != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected text, json or sarif\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+
+	rules, err := LoadRules(cmd.rulesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitUsageError
+	}
+
+	args := f.Args()
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+	scope, err := pkgset.Calc(ctx, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	violations, err := Check(pkggraph.From(scope), rules, CalcResolver(ctx, ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.rulesFile, err)
+		return subcommands.ExitFailure
+	}
+
+	switch output {
+	case "json":
+		err = WriteJSON(os.Stdout, violations)
+	case "sarif":
+		err = SARIF(sarif.Root(ctx), violations).Write(os.Stdout)
+	default:
+		err = WriteText(os.Stdout, violations)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stderr, "%d violations\n", len(violations))
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
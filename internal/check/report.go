@@ -0,0 +1,103 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/sarif"
+)
+
+// Position formats the position of the import spec as "file:line",
+// with the file relative to the working directory when inside it.
+func (v *Violation) Position() string {
+	if v.Import.File == "" {
+		return v.From
+	}
+	return fmt.Sprintf("%s:%d", relative(v.Import.File), v.Import.Line)
+}
+
+// relative returns filename relative to the working directory when inside it.
+func relative(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filename
+	}
+	return rel
+}
+
+// WriteText writes a line per violation in the form:
+//
+//	file:line: from imports to (rule)
+func WriteText(w io.Writer, violations []Violation) error {
+	for _, v := range violations {
+		if _, err := fmt.Fprintf(w, "%s: %s imports %s (%s)\n", v.Position(), v.From, v.To, v.Rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes violations as a JSON array.
+func WriteJSON(w io.Writer, violations []Violation) error {
+	type violation struct {
+		Rule     string
+		RuleLine int
+		From     string
+		To       string
+		File     string `json:",omitempty"`
+		Line     int    `json:",omitempty"`
+	}
+	out := []violation{}
+	for _, v := range violations {
+		out = append(out, violation{
+			Rule:     v.Rule.String(),
+			RuleLine: v.Rule.Line,
+			From:     v.From,
+			To:       v.To,
+			File:     v.Import.File,
+			Line:     v.Import.Line,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+// ruleDescriptions describe the kinds of rules in SARIF logs.
+var ruleDescriptions = []sarif.Rule{
+	{ID: ruleID(Deny), Description: "Import of a package denied by a rule."},
+	{ID: ruleID(AllowOnly), Description: "Import of a package that only specific packages may import."},
+	{ID: ruleID(Layers), Description: "Import of a package in a higher layer."},
+}
+
+// ruleID returns the SARIF rule ID of a kind, e.g. "allow-only".
+func ruleID(kind Kind) string {
+	return strings.ReplaceAll(string(kind), " ", "-")
+}
+
+// SARIF returns the violations as SARIF results, with files relative to root.
+func SARIF(root string, violations []Violation) *sarif.Log {
+	log := &sarif.Log{Root: root}
+	for _, rule := range ruleDescriptions {
+		log.AddRule(rule)
+	}
+	for _, v := range violations {
+		log.Add(sarif.Result{
+			RuleID:  ruleID(v.Rule.Kind),
+			Level:   sarif.Error,
+			Message: fmt.Sprintf("%s imports %s (%s)", v.From, v.To, v.Rule),
+			File:    v.Import.File,
+			Line:    v.Import.Line,
+		})
+	}
+	return log
+}
@@ -0,0 +1,154 @@
+// Package check verifies the imports between packages against architecture rules.
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Kind is the kind of rule.
+type Kind string
+
+const (
+	// Deny forbids packages in From to import packages in To.
+	Deny Kind = "deny"
+	// AllowOnly forbids packages not in From to import packages in To,
+	// except for imports between packages in To.
+	AllowOnly Kind = "allow only"
+	// Layers forbids packages in a layer to import packages in the layers above it.
+	Layers Kind = "layers"
+)
+
+// Rule is a dependency rule between package expressions.
+type Rule struct {
+	Kind Kind
+	// From and To are the package expressions of deny and allow only rules.
+	From, To string
+	// Layers are the package expressions of the layers, from top to bottom.
+	Layers []string
+	// Line is the line of the rule in the rules file.
+	Line int
+}
+
+// String formats the rule as in the rules file.
+func (rule *Rule) String() string {
+	if rule.Kind == Layers {
+		return string(rule.Kind) + ": [" + strings.Join(rule.Layers, ", ") + "]"
+	}
+	return string(rule.Kind) + ": " + rule.From + " -> " + rule.To
+}
+
+// LoadRules reads rules from a file.
+func LoadRules(path string) ([]*Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	rules, err := ParseRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules parses the rules file format, which contains one rule per line:
+//
+//	# comment
+//	deny: ./domain/... -> ./infra/...
+//	allow only: ./cmd/... -> ./internal/wiring
+//	layers: [./cmd/..., ./internal/app/..., ./domain/...]
+//
+// The packages are package expressions, see "help expr".
+func ParseRules(r io.Reader) ([]*Rule, error) {
+	var rules []*Rule
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+		value = strings.TrimSpace(value)
+
+		rule := &Rule{Kind: Kind(strings.Join(strings.Fields(key), " ")), Line: lineNumber}
+		var err error
+		switch rule.Kind {
+		case Deny, AllowOnly:
+			rule.From, rule.To, err = parseEdge(value)
+		case Layers:
+			rule.Layers, err = parseLayers(value)
+		default:
+			err = fmt.Errorf("unknown rule %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// parseEdge parses "A -> B".
+func parseEdge(value string) (from, to string, err error) {
+	from, to, ok := strings.Cut(value, "->")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !ok || from == "" || to == "" {
+		return "", "", fmt.Errorf("expected \"A -> B\", got %q", value)
+	}
+	return from, to, nil
+}
+
+// parseLayers parses "[A, B, C]", where the expressions may contain
+// commas inside parentheses.
+func parseLayers(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("expected \"[A, B, ...]\", got %q", value)
+	}
+	value = value[1 : len(value)-1]
+
+	var layers []string
+	depth, start := 0, 0
+	add := func(end int) error {
+		layer := strings.TrimSpace(value[start:end])
+		if layer == "" {
+			return fmt.Errorf("empty layer in %q", value)
+		}
+		layers = append(layers, layer)
+		return nil
+	}
+	for i, r := range value {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				if err := add(i); err != nil {
+					return nil, err
+				}
+				start = i + 1
+			}
+		}
+	}
+	if err := add(len(value)); err != nil {
+		return nil, err
+	}
+	if len(layers) < 2 {
+		return nil, fmt.Errorf("expected at least two layers, got %d", len(layers))
+	}
+	return layers, nil
+}
@@ -0,0 +1,41 @@
+package check
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader(`
+# comment
+deny: ./domain/... -> ./infra/...
+allow  only: ./cmd/... -> ./internal/wiring
+layers: [./cmd/..., reach(./app/..., ./domain/...), ./domain/...]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"deny: ./domain/... -> ./infra/...",
+		"allow only: ./cmd/... -> ./internal/wiring",
+		"layers: [./cmd/..., reach(./app/..., ./domain/...), ./domain/...]",
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(rules), len(want))
+	}
+	for i, rule := range rules {
+		if rule.String() != want[i] {
+			t.Errorf("rule %d: got %q, want %q", i, rule, want[i])
+		}
+	}
+	if rules[0].Line != 3 || len(rules[2].Layers) != 3 {
+		t.Errorf("got %+v", rules)
+	}
+
+	for _, invalid := range []string{"deny: a", "deny: -> b", "layers: a, b", "layers: [a]", "layers: [a,,b]", "forbid: a -> b", "a -> b"} {
+		if _, err := ParseRules(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cochange
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+type Command struct {
+	printStandard bool
+	typesMode     bool
+
+	since       string
+	commits     int
+	maxPackages int
+	minShared   int
+	minCoupling float64
+	unlinked    bool
+
+	output  string
+	noAlign bool
+}
+
+func (*Command) Name() string     { return "cochange" }
+func (*Command) Synopsis() string { return "List packages that change together in git history." }
+func (*Command) Usage() string {
+	return `cochange <expr>:
+	List pairs of packages that are changed by the same commits, using
+	"git log --name-only" of the repository in the current directory.
+	Changed files are mapped to packages via their Go files.
+
+	Columns:
+	  Shared    Commits changing both packages.
+	  Coupling  Shared divided by the average number of commits changing
+	            either package (0..1).
+	  Edge      import when either package imports the other, implements
+	            when a type in one satisfies an interface of the other
+	            (with -types) and none otherwise.
+
+	Pairs without an edge are logical couplings that are not visible in
+	the import graph. -unlinked lists only those. The pairs can also be
+	drawn on the dependency graph with "graph -cochange N".
+
+	Commits changing more than -max-packages packages are ignored, since
+	large refactorings would couple everything.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.typesMode, "types", false, "detect structural coupling between the packages")
+
+	f.StringVar(&cmd.since, "since", "", "only consider commits more recent than a date, e.g. \"6 months ago\"")
+	f.IntVar(&cmd.commits, "commits", 1000, "maximum number of commits to consider, 0 for unlimited")
+	f.IntVar(&cmd.maxPackages, "max-packages", 30, "ignore commits changing more packages, 0 for unlimited")
+	f.IntVar(&cmd.minShared, "min", 2, "minimum number of commits changing both packages")
+	f.Float64Var(&cmd.minCoupling, "min-coupling", 0, "minimum coupling (0..1)")
+	f.BoolVar(&cmd.unlinked, "unlinked", false, "list only pairs without an import or structural edge")
+
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json)")
+	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected text or json\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.CalcWithOpts(ctx, f.Args(), pkgset.CalcOpts{
+		TypesMode: cmd.typesMode,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	var impls []pkggraph.Implementation
+	if cmd.typesMode {
+		impls = pkggraph.From(result).FindImplementations()
+	}
+
+	pairs, err := Find(ctx, result, impls, cmd.logOpts(), cmd.opts())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	if cmd.unlinked {
+		var filtered []Pair
+		for _, pair := range pairs {
+			if !pair.Linked() {
+				filtered = append(filtered, pair)
+			}
+		}
+		pairs = filtered
+	}
+
+	if output == "json" {
+		err = WriteJSON(os.Stdout, pairs)
+	} else {
+		err = WriteText(os.Stdout, pairs, cmd.noAlign)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+func (cmd *Command) logOpts() git.LogOpts {
+	return git.LogOpts{Since: cmd.since, MaxCount: cmd.commits}
+}
+
+func (cmd *Command) opts() Opts {
+	return Opts{
+		MaxPackages: cmd.maxPackages,
+		MinShared:   cmd.minShared,
+		MinCoupling: cmd.minCoupling,
+	}
+}
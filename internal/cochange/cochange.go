@@ -0,0 +1,225 @@
+// Package cochange finds packages that change together in the git history.
+package cochange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+// Opts configures Analyze.
+type Opts struct {
+	// MaxPackages ignores commits changing more packages, such as large
+	// refactorings, which would couple everything. Unlimited when zero.
+	MaxPackages int
+	// MinShared is the minimum number of commits changing both packages.
+	MinShared int
+	// MinCoupling is the minimum Coupling of a pair.
+	MinCoupling float64
+}
+
+// Pair is two packages changing together.
+type Pair struct {
+	// A and B are the package IDs, A < B.
+	A, B string
+
+	// Shared is the number of commits changing both packages.
+	Shared int
+	// CommitsA and CommitsB are the number of commits changing A and B.
+	CommitsA, CommitsB int
+	// Coupling is Shared divided by the average of CommitsA and CommitsB (0..1).
+	Coupling float64
+
+	// Import is set when either package imports the other.
+	Import bool
+	// Structural is set when a type in either package satisfies an
+	// interface of the other, filled when types are loaded.
+	Structural bool
+}
+
+// Linked returns whether the packages are coupled via imports or structurally.
+func (pair *Pair) Linked() bool { return pair.Import || pair.Structural }
+
+// Edge describes how the packages are linked: import, implements or none.
+func (pair *Pair) Edge() string {
+	switch {
+	case pair.Import:
+		return "import"
+	case pair.Structural:
+		return "implements"
+	default:
+		return "none"
+	}
+}
+
+// FileIndex maps the absolute paths of the Go files to the IDs of their packages.
+type FileIndex map[string][]string
+
+// IndexFiles creates a FileIndex from the GoFiles of pkgs.
+func IndexFiles(pkgs map[string]*packages.Package) FileIndex {
+	index := FileIndex{}
+	for id, p := range pkgs {
+		for _, file := range p.GoFiles {
+			file = filepath.Clean(file)
+			index[file] = append(index[file], id)
+		}
+	}
+	for _, ids := range index {
+		sort.Strings(ids)
+	}
+	return index
+}
+
+// Analyze computes the package pairs changing together in commits.
+// The pairs are sorted by Shared and Coupling, highest first.
+func Analyze(commits []git.Commit, index FileIndex, opts Opts) []Pair {
+	type key struct{ a, b string }
+
+	counts := map[string]int{}
+	shared := map[key]int{}
+
+	for _, commit := range commits {
+		changed := map[string]bool{}
+		for _, file := range commit.Files {
+			for _, id := range index[filepath.Clean(file.Path)] {
+				changed[id] = true
+			}
+		}
+		if opts.MaxPackages > 0 && len(changed) > opts.MaxPackages {
+			continue
+		}
+
+		ids := make([]string, 0, len(changed))
+		for id := range changed {
+			ids = append(ids, id)
+			counts[id]++
+		}
+		sort.Strings(ids)
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				shared[key{a, b}]++
+			}
+		}
+	}
+
+	var pairs []Pair
+	for k, n := range shared {
+		if n < opts.MinShared {
+			continue
+		}
+		pair := Pair{
+			A: k.a, B: k.b,
+			Shared:   n,
+			CommitsA: counts[k.a], CommitsB: counts[k.b],
+		}
+		pair.Coupling = float64(n) / (float64(pair.CommitsA+pair.CommitsB) / 2)
+		if pair.Coupling < opts.MinCoupling {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, k int) bool {
+		a, b := &pairs[i], &pairs[k]
+		switch {
+		case a.Shared != b.Shared:
+			return a.Shared > b.Shared
+		case a.Coupling != b.Coupling:
+			return a.Coupling > b.Coupling
+		case a.A != b.A:
+			return a.A < b.A
+		default:
+			return a.B < b.B
+		}
+	})
+	return pairs
+}
+
+// Find analyzes the history of the git repository in the current directory
+// for pkgs, linking the pairs with the imports of pkgs and impls.
+func Find(ctx context.Context, pkgs map[string]*packages.Package, impls []pkggraph.Implementation, log git.LogOpts, opts Opts) ([]Pair, error) {
+	commits, err := git.Log(ctx, ".", log)
+	if err != nil {
+		return nil, err
+	}
+	pairs := Analyze(commits, IndexFiles(pkgs), opts)
+	Link(pairs, pkgs, impls)
+	return pairs, nil
+}
+
+// Link sets Import and Structural of pairs from the packages and
+// the implementations found in them.
+func Link(pairs []Pair, pkgs map[string]*packages.Package, impls []pkggraph.Implementation) {
+	type key struct{ a, b string }
+	structural := map[key]bool{}
+	for _, impl := range impls {
+		structural[key{impl.From.ID, impl.To.ID}] = true
+		structural[key{impl.To.ID, impl.From.ID}] = true
+	}
+
+	imports := func(from, to string) bool {
+		p, ok := pkgs[from]
+		if !ok {
+			return false
+		}
+		_, ok = p.Imports[pkgs[to].PkgPath]
+		return ok
+	}
+
+	for i := range pairs {
+		pair := &pairs[i]
+		if pkgs[pair.A] != nil && pkgs[pair.B] != nil {
+			pair.Import = imports(pair.A, pair.B) || imports(pair.B, pair.A)
+		}
+		pair.Structural = structural[key{pair.A, pair.B}]
+	}
+}
+
+// Overlay adds the pairs to graph as co-change edges. Pairs without an
+// edge in the graph become edges for which CoChangeOnly is true.
+func Overlay(graph *pkggraph.Graph, pairs []Pair) {
+	for _, pair := range pairs {
+		a, b := graph.Packages[pair.A], graph.Packages[pair.B]
+		if a == nil || b == nil {
+			continue
+		}
+		graph.AddCoChange(a, b, pair.Shared)
+	}
+}
+
+// WriteText writes pairs as a table.
+func WriteText(out io.Writer, pairs []Pair, noAlign bool) error {
+	var w io.Writer = out
+	if !noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintln(w, "A\tB\tShared\tCoupling\tEdge")
+	for _, pair := range pairs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%s\n", pair.A, pair.B, pair.Shared, pair.Coupling, pair.Edge())
+	}
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+	return nil
+}
+
+// WriteJSON writes pairs as a JSON array.
+func WriteJSON(w io.Writer, pairs []Pair) error {
+	if pairs == nil {
+		pairs = []Pair{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(pairs)
+}
@@ -0,0 +1,72 @@
+package cochange
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+func testPackages() map[string]*packages.Package {
+	pkg := func(id string, files ...string) *packages.Package {
+		return &packages.Package{ID: id, PkgPath: id, GoFiles: files, Imports: map[string]*packages.Package{}}
+	}
+	pkgs := map[string]*packages.Package{
+		"a": pkg("a", "/repo/a/a.go"),
+		"b": pkg("b", "/repo/b/b.go", "/repo/b/b2.go"),
+		"c": pkg("c", "/repo/c/c.go"),
+	}
+	pkgs["a"].Imports["b"] = pkgs["b"]
+	return pkgs
+}
+
+func TestAnalyze(t *testing.T) {
+	pkgs := testPackages()
+	commits := []git.Commit{
+		{Hash: "1", Files: []git.File{{Path: "/repo/a/a.go"}, {Path: "/repo/b/b.go"}, {Path: "/repo/b/b2.go"}}},
+		{Hash: "2", Files: []git.File{{Path: "/repo/a/a.go"}, {Path: "/repo/c/c.go"}}},
+		{Hash: "3", Files: []git.File{{Path: "/repo/a/a.go"}, {Path: "/repo/c/c.go"}, {Path: "/repo/README.md"}}},
+		{Hash: "4", Files: []git.File{{Path: "/repo/c/c.go"}}},
+		{Hash: "5", Files: []git.File{{Path: "/repo/a/a.go"}, {Path: "/repo/b/b.go"}, {Path: "/repo/c/c.go"}}},
+	}
+
+	pairs := Analyze(commits, IndexFiles(pkgs), Opts{MaxPackages: 2, MinShared: 1})
+	Link(pairs, pkgs, nil)
+
+	want := []Pair{
+		{A: "a", B: "c", Shared: 2, CommitsA: 3, CommitsB: 3, Coupling: 2.0 / 3},
+		{A: "a", B: "b", Shared: 1, CommitsA: 3, CommitsB: 1, Coupling: 0.5, Import: true},
+	}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("got %+v, want %+v", pairs, want)
+	}
+
+	if got := Analyze(commits, IndexFiles(pkgs), Opts{MaxPackages: 2, MinShared: 2}); len(got) != 1 {
+		t.Errorf("got %d pairs with MinShared 2, want 1", len(got))
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	pkgs := testPackages()
+	graph := pkggraph.From(pkgs)
+
+	Overlay(graph, []Pair{
+		{A: "a", B: "b", Shared: 3, Import: true},
+		{A: "a", B: "c", Shared: 2},
+	})
+
+	ab := graph.Packages["a"].EdgeTo(graph.Packages["b"])
+	if ab.CoChanges != 3 || ab.CoChangeOnly() {
+		t.Errorf("a->b: got %d co-changes, co-change only %v", ab.CoChanges, ab.CoChangeOnly())
+	}
+	ac := graph.Packages["a"].EdgeTo(graph.Packages["c"])
+	if ac == nil || ac.CoChanges != 2 || !ac.CoChangeOnly() {
+		t.Errorf("a->c: got %+v, want co-change only edge", ac)
+	}
+	if got := graph.Packages["a"].ImportsNodes; len(got) != 1 || got[0].ID != "b" {
+		t.Errorf("a: got imports %v, want only b", got)
+	}
+}
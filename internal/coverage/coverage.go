@@ -0,0 +1,127 @@
+// Package coverage reads Go test coverage profiles.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+// Counts are the number of statements in a package.
+type Counts struct {
+	Statements int
+	Covered    int
+}
+
+// Profile contains the statement counts by package path.
+type Profile map[string]*Counts
+
+// Load reads a profile written by "go test -coverprofile".
+func Load(filename string) (Profile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	profile, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return profile, nil
+}
+
+// Parse parses a coverage profile, which consists of "mode:" lines
+// followed by blocks in the form:
+//
+//	file.go:line.column,line.column statements count
+//
+// Blocks are keyed by file and position, so that repeated blocks, e.g.
+// from concatenated profiles, count as covered when any of them is.
+func Parse(r io.Reader) (Profile, error) {
+	type block struct {
+		pkg        string
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		colon := strings.LastIndex(line, ":")
+		if len(fields) < 3 || colon < 0 {
+			return nil, fmt.Errorf("line %d: invalid block %q", lineno, line)
+		}
+		statements, err := strconv.Atoi(fields[len(fields)-2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid statement count: %w", lineno, err)
+		}
+		count, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid count: %w", lineno, err)
+		}
+
+		file := line[:colon]
+		key := strings.Join(fields[:len(fields)-2], " ")
+		b, ok := blocks[key]
+		if !ok {
+			b = &block{pkg: path.Dir(filepath.ToSlash(file)), statements: statements}
+			blocks[key] = b
+			order = append(order, key)
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	profile := Profile{}
+	for _, key := range order {
+		b := blocks[key]
+		counts, ok := profile[b.pkg]
+		if !ok {
+			counts = &Counts{}
+			profile[b.pkg] = counts
+		}
+		counts.Statements += b.statements
+		if b.covered {
+			counts.Covered += b.statements
+		}
+	}
+	return profile, nil
+}
+
+// lookup returns the counts of the package of n, by import path or,
+// for packages outside of a module, by directory.
+func (profile Profile) lookup(n *pkggraph.Node) *Counts {
+	if counts, ok := profile[n.PkgPath]; ok {
+		return counts
+	}
+	if len(n.GoFiles) > 0 {
+		return profile[filepath.ToSlash(filepath.Dir(n.GoFiles[0]))]
+	}
+	return nil
+}
+
+// Apply sets the coverage of the nodes in graph.
+func Apply(graph *pkggraph.Graph, profile Profile) {
+	for _, n := range graph.Sorted {
+		if counts := profile.lookup(n); counts != nil {
+			n.SetCoverage(counts.Statements, counts.Covered)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package coverage
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+const testProfile = `mode: set
+example.com/x/a/a.go:3.10,5.2 2 1
+example.com/x/a/a.go:7.10,9.2 3 0
+example.com/x/b/b.go:3.10,5.2 4 0
+mode: set
+example.com/x/b/b.go:3.10,5.2 4 1
+example.com/x/b/b.go:7.10,9.2 1 0
+`
+
+func TestParse(t *testing.T) {
+	profile, err := Parse(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Profile{
+		"example.com/x/a": {Statements: 5, Covered: 2},
+		"example.com/x/b": {Statements: 5, Covered: 4},
+	}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("got %v, want %v", profile, want)
+	}
+
+	if _, err := Parse(strings.NewReader("a.go:1.1,2.2 x 1\n")); err == nil {
+		t.Error("expected an error for an invalid statement count")
+	}
+}
+
+func TestApply(t *testing.T) {
+	pkg := func(path string) *packages.Package {
+		return &packages.Package{ID: path, PkgPath: path, Imports: map[string]*packages.Package{}}
+	}
+	pkgs := map[string]*packages.Package{
+		"example.com/x/a": pkg("example.com/x/a"),
+		"example.com/x/b": pkg("example.com/x/b"),
+		"example.com/x/c": pkg("example.com/x/c"),
+	}
+	profile, err := Parse(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := pkggraph.From(pkgs)
+	Apply(graph, profile)
+
+	if got := graph.Packages["example.com/x/a"].Coverage; got != 40 {
+		t.Errorf("a: got coverage %v, want 40", got)
+	}
+	if got := graph.Packages["example.com/x/c"].Statements; got != 0 {
+		t.Errorf("c: got %d statements, want 0", got)
+	}
+	if got := graph.Packages["example.com/x/c"].CoverageColor(); got != "#bab0ac" {
+		t.Errorf("c: got color %q, want gray", got)
+	}
+	if got := graph.Packages["example.com/x/b"].CoverageColor(); got != "#749251" {
+		t.Errorf("b: got color %q, want #749251", got)
+	}
+
+	collapsed := graph.Collapse(pkggraph.ByRegexp(regexp.MustCompile(`^example.com/x`)))
+	if got := collapsed.Packages["example.com/x"].Coverage; got != 60 {
+		t.Errorf("collapsed: got coverage %v, want 60", got)
+	}
+}
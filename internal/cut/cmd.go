@@ -17,14 +17,17 @@ import (
 
 	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
 	"github.com/flamingoosesoftwareinc/goda/internal/stat"
 	"github.com/flamingoosesoftwareinc/goda/internal/templates"
 )
 
 type Command struct {
 	printStandard bool
+	hiddenEdges   bool
 	exclude       string
 
+	output  string
 	noAlign bool
 	header  string
 	format  string
@@ -45,7 +48,9 @@ func (*Command) Usage() string {
 func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 	f.StringVar(&cmd.exclude, "exclude", "", "package expr to exclude from output")
+	f.BoolVar(&cmd.hiddenEdges, "hidden", false, "add edges for dependencies through excluded packages")
 
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json, ndjson, csv)")
 	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
 	f.StringVar(&cmd.header, "h", "", "header for the table\nautomatically derives from format, when empty, use \"-\" to skip")
 	f.StringVar(&cmd.format, "f", "{{.ID}}\t{{.InDegree}}\t{{.Cut.PackageCount}}\t{{.Cut.AllFiles.Size}}\t{{.Cut.Go.Lines}}", "info formatting")
@@ -58,6 +63,15 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
+	var records record.Writer
+	if !record.IsText(cmd.output) {
+		records, err = record.NewWriter(cmd.output, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+	}
+
 	if !cmd.printStandard {
 		go pkgset.LoadStd()
 	}
@@ -81,7 +95,9 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		result = pkgset.Subtract(result, pkgset.Std())
 	}
 
-	graph := pkggraph.From(result)
+	graph := pkggraph.FromWithOpts(result, pkggraph.FromOpts{
+		HiddenEdges: cmd.hiddenEdges,
+	})
 
 	nodes := map[string]*Node{}
 	nodelist := []*Node{}
@@ -129,6 +145,29 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return nodelist[i].InDegree() < nodelist[k].InDegree()
 	})
 
+	if records != nil {
+		for _, node := range nodelist {
+			if _, exclude := excluded[node.ID]; exclude {
+				continue
+			}
+			r := record.FromNode(node.Node)
+			r.Cut = &record.Cut{
+				InDegree:  node.InDegree(),
+				OutDegree: node.OutDegree(),
+				Stat:      node.Cut,
+			}
+			if err := records.Write(r); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+				return subcommands.ExitFailure
+			}
+		}
+		if err := records.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
 	var w io.Writer = os.Stdout
 	if !cmd.noAlign {
 		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Commit is a commit with the files it changed.
+type Commit struct {
+	Hash  string
+	Files []File
+}
+
+// File is a file changed by a commit.
+type File struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Added and Deleted are the changed lines, with LogOpts.Lines.
+	// Both are zero for binary files.
+	Added, Deleted int
+}
+
+// LogOpts configures the range of commits returned by Log.
+type LogOpts struct {
+	// Since limits the commits to the ones more recent than a date,
+	// in any format accepted by "git log --since", e.g. "6 months ago".
+	Since string
+	// MaxCount limits the number of commits, unlimited when zero.
+	MaxCount int
+	// Lines counts the added and deleted lines of each file.
+	Lines bool
+}
+
+// Log returns the non-merge commits reachable from HEAD of the repository
+// containing dir, newest first, using "git log --name-only" or
+// "git log --numstat" with opts.Lines.
+func Log(ctx context.Context, dir string, opts LogOpts) ([]Commit, error) {
+	root, err := Toplevel(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"log", "--no-merges", "--no-renames", "--format=%x00%H"}
+	if opts.Lines {
+		args = append(args, "--numstat")
+	} else {
+		args = append(args, "--name-only")
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.MaxCount > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.MaxCount))
+	}
+
+	out, err := run(ctx, root, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(root, out), nil
+}
+
+// parseLog parses the output of "git log --format=%x00%H" with
+// either --name-only or --numstat.
+func parseLog(root, out string) []Commit {
+	var commits []Commit
+	for entry := range strings.SplitSeq(out, "\x00") {
+		lines := strings.Split(strings.TrimSpace(entry), "\n")
+		if lines[0] == "" {
+			continue
+		}
+		commit := Commit{Hash: lines[0]}
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				commit.Files = append(commit.Files, parseFile(root, line))
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// parseFile parses a line of --name-only or --numstat output,
+// the latter being "added<TAB>deleted<TAB>path" with "-" for binary files.
+func parseFile(root, line string) File {
+	var file File
+	if added, rest, ok := strings.Cut(line, "\t"); ok {
+		if deleted, path, ok := strings.Cut(rest, "\t"); ok {
+			file.Added, _ = strconv.Atoi(added)
+			file.Deleted, _ = strconv.Atoi(deleted)
+			line = path
+		}
+	}
+	file.Path = filepath.Join(root, filepath.FromSlash(line))
+	return file
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLog(t *testing.T) {
+	out := "\x00abc\n\na/a.go\nb/b.go\n\x00def\n\n\x00123\n\nc.go\n"
+	got := parseLog("/repo", out)
+	want := []Commit{
+		{Hash: "abc", Files: []File{{Path: filepath.Join("/repo", "a", "a.go")}, {Path: filepath.Join("/repo", "b", "b.go")}}},
+		{Hash: "def"},
+		{Hash: "123", Files: []File{{Path: filepath.Join("/repo", "c.go")}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseLogNumStat(t *testing.T) {
+	out := "\x00abc\n\n3\t1\ta/a.go\n-\t-\timage.png\n"
+	got := parseLog("/repo", out)
+	want := []Commit{
+		{Hash: "abc", Files: []File{
+			{Path: filepath.Join("/repo", "a", "a.go"), Added: 3, Deleted: 1},
+			{Path: filepath.Join("/repo", "image.png")},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
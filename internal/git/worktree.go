@@ -0,0 +1,96 @@
+// Package git implements helpers for querying the local git repository.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Toplevel returns the root directory of the repository containing dir.
+func Toplevel(ctx context.Context, dir string) (string, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Worktree is a temporary checkout of a revision.
+type Worktree struct {
+	// Rev is the checked out revision.
+	Rev string
+	// Root is the root directory of the worktree.
+	Root string
+	// Dir is the directory inside the worktree that corresponds
+	// to the directory the worktree was created from.
+	Dir string
+
+	repo string
+}
+
+// AddWorktree checks out rev into a temporary detached worktree.
+// The worktree must be removed with Remove after use.
+func AddWorktree(ctx context.Context, rev string) (*Worktree, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := Toplevel(ctx, wd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve symlinks so that the relative directory is computed correctly.
+	realwd, err := filepath.EvalSymlinks(wd)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(repo, realwd)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.MkdirTemp("", "goda-worktree-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := run(ctx, repo, "worktree", "add", "--detach", "--quiet", root, rev); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to check out %q: %w", rev, err)
+	}
+
+	return &Worktree{
+		Rev:  rev,
+		Root: root,
+		Dir:  filepath.Join(root, rel),
+		repo: repo,
+	}, nil
+}
+
+// Remove deletes the worktree.
+func (w *Worktree) Remove(ctx context.Context) error {
+	_, err := run(ctx, w.repo, "worktree", "remove", "--force", w.Root)
+	if rerr := os.RemoveAll(w.Root); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// run executes git with args in dir and returns the stdout.
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -7,9 +7,13 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/google/subcommands"
 
+	"github.com/flamingoosesoftwareinc/goda/internal/cochange"
+	"github.com/flamingoosesoftwareinc/goda/internal/coverage"
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
 	"github.com/flamingoosesoftwareinc/goda/internal/templates"
@@ -25,9 +29,29 @@ type Command struct {
 
 	nocolor bool
 	colors  exprColors
+	colorBy string
+
+	coverProfile string
 
 	clusters bool
 	shortID  bool
+
+	hiddenEdges bool
+	structural  bool
+
+	cochange            int
+	cochangeSince       string
+	cochangeCommits     int
+	cochangeMaxPackages int
+
+	sdp bool
+
+	focus     string
+	radius    int
+	direction string
+	dim       bool
+
+	collapse string
 }
 
 func (*Command) Name() string     { return "graph" }
@@ -50,6 +74,53 @@ Supported output types:
 
 	mermaid - mermaid flowchart
 
+Focus mode:
+
+	-focus selects packages to center the graph on, only packages within
+	-radius hops following -direction are printed. Truncated edges are
+	summarized by "+k more" stub nodes. With -dim the remaining packages
+	are printed in gray instead of being elided, except for packages
+	colored by -color. -radius, -direction and -dim require -focus.
+
+Structural edges:
+
+	-structural adds dashed edges labeled "implements" from packages
+	with concrete types to packages with interfaces they satisfy,
+	when neither package imports the other. See "help implements".
+
+Co-change edges:
+
+	-cochange N labels edges between packages changed together by at
+	least N commits with "co-changed" and adds red dashed edges between
+	such packages without an import, see "help cochange".
+	-cochange-since, -cochange-commits and -cochange-max-packages
+	select the commits, same as -since, -commits and -max-packages
+	of "goda cochange".
+
+Stable dependencies:
+
+	-sdp computes the package metrics and colors the imports of less
+	stable packages red, where I(importer) < I(imported). With -collapse
+	the metrics are computed between the groups. See "help metrics".
+
+Coverage:
+
+	-coverprofile cover.out reads a "go test -coverprofile" file into
+	.Coverage of the packages, which can be used in -f labels.
+	-colorby coverage colors the packages from red (uncovered) to green
+	(covered), packages missing from the profile are gray.
+
+Collapsing:
+
+	-collapse merges packages into group nodes, edges between groups
+	are labeled with the number of package imports they represent.
+	The edges and digraph output types list each edge once
+	without the number of imports.
+
+	module - group by module
+	dir=N  - group by the first N directories inside the module
+	regexp - group by the first submatch (or the whole match) of regexp
+
 	See "help expr" for further information about expressions.
 	See "help format" for further information about formatting.
 `
@@ -60,6 +131,8 @@ func (cmd *Command) SetFlags(f *flag.FlagSet) {
 
 	f.BoolVar(&cmd.nocolor, "nocolor", false, "disable coloring")
 	f.Var(&cmd.colors, "color", "specify a color for packages in a given expr (e.g. `-color red=./...`)")
+	f.StringVar(&cmd.colorBy, "colorby", "", "color packages by a metric (coverage)")
+	f.StringVar(&cmd.coverProfile, "coverprofile", "", "read test coverage from a \"go test -coverprofile\" file into .Coverage")
 
 	f.StringVar(&cmd.docs, "docs", "https://pkg.go.dev/", "override the docs url to use")
 
@@ -68,6 +141,20 @@ func (cmd *Command) SetFlags(f *flag.FlagSet) {
 
 	f.BoolVar(&cmd.clusters, "cluster", false, "create clusters")
 	f.BoolVar(&cmd.shortID, "short", false, "use short package id-s inside clusters")
+	f.BoolVar(&cmd.hiddenEdges, "hidden", false, "add dashed edges for dependencies through excluded packages")
+	f.BoolVar(&cmd.structural, "structural", false, "add dashed edges from types to the interfaces they satisfy in unconnected packages")
+	f.IntVar(&cmd.cochange, "cochange", 0, "add edges between packages changed together by at least N commits, 0 to disable")
+	f.StringVar(&cmd.cochangeSince, "cochange-since", "", "only consider commits more recent than a date for -cochange, e.g. \"6 months ago\"")
+	f.IntVar(&cmd.cochangeCommits, "cochange-commits", 1000, "maximum number of commits to consider for -cochange, 0 for unlimited")
+	f.IntVar(&cmd.cochangeMaxPackages, "cochange-max-packages", 30, "ignore commits changing more packages for -cochange, 0 for unlimited")
+	f.BoolVar(&cmd.sdp, "sdp", false, "color imports of less stable packages red (Stable Dependencies Principle)")
+
+	f.StringVar(&cmd.focus, "focus", "", "package expr to focus the graph on")
+	f.IntVar(&cmd.radius, "radius", 1, "maximum hops from the focused packages, negative for unlimited")
+	f.StringVar(&cmd.direction, "direction", "both", "direction to follow from the focused packages (up, down, both)")
+	f.BoolVar(&cmd.dim, "dim", false, "dim packages outside of focus instead of eliding them")
+
+	f.StringVar(&cmd.collapse, "collapse", "", "collapse packages into groups (module, dir=N, regexp)")
 }
 
 func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
@@ -86,6 +173,143 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
+	format, err := cmd.newFormat(label)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	if cmd.colorBy != "" && cmd.colorBy != "coverage" {
+		fmt.Fprintf(os.Stderr, "unknown -colorby %q, expected coverage\n", cmd.colorBy)
+		return subcommands.ExitUsageError
+	}
+
+	if cmd.focus == "" {
+		var focusFlag string
+		f.Visit(func(fl *flag.Flag) {
+			switch fl.Name {
+			case "radius", "direction", "dim":
+				focusFlag = fl.Name
+			}
+		})
+		if focusFlag != "" {
+			fmt.Fprintf(os.Stderr, "-%s requires -focus\n", focusFlag)
+			return subcommands.ExitUsageError
+		}
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.CalcWithOpts(ctx, f.Args(), pkgset.CalcOpts{
+		TypesMode: cmd.structural,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	allPkgs := result
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.FromWithOpts(result, pkggraph.FromOpts{
+		HiddenEdges: cmd.hiddenEdges,
+	})
+	if cmd.sdp {
+		graph.ComputeMetrics(allPkgs)
+	}
+	centrality := pkggraph.UsesCentrality(cmd.labelFormat)
+	if centrality {
+		graph.ComputeCentrality()
+	}
+	if cmd.coverProfile != "" {
+		profile, err := coverage.Load(cmd.coverProfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		coverage.Apply(graph, profile)
+	}
+
+	var impls []pkggraph.Implementation
+	if cmd.structural {
+		impls = graph.FindImplementations()
+		graph.AddStructuralEdges(impls)
+	}
+	if cmd.cochange > 0 {
+		pairs, err := cochange.Find(ctx, result, impls,
+			git.LogOpts{Since: cmd.cochangeSince, MaxCount: cmd.cochangeCommits},
+			cochange.Opts{MaxPackages: cmd.cochangeMaxPackages, MinShared: cmd.cochange})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		cochange.Overlay(graph, pairs)
+	}
+	for _, color := range cmd.colors {
+		target, err := pkgset.Calc(ctx, []string{color.Expr})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to evaluate color expression %q: %v", color.Expr, err)
+			continue
+		}
+		for id := range target {
+			if n, ok := graph.Packages[id]; ok {
+				n.Color = color.Color
+			}
+		}
+	}
+
+	if cmd.focus != "" {
+		graph, err = cmd.focusGraph(ctx, graph)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	}
+
+	if cmd.collapse != "" {
+		group, err := pkggraph.ParseGroup(cmd.collapse)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		graph = graph.Collapse(group)
+		if cmd.sdp {
+			graph.ComputeGroupMetrics(allPkgs, group, pkggraph.MetricsOpts{})
+		}
+		if centrality {
+			graph.ComputeCentrality()
+		}
+	}
+
+	if cmd.colorBy == "coverage" {
+		for _, n := range graph.Sorted {
+			if n.Stub == 0 {
+				n.Color = n.CoverageColor()
+			}
+		}
+	}
+
+	for _, n := range graph.Sorted {
+		for _, e := range n.Edges {
+			if e.CoChangeOnly() || (cmd.sdp && e.BreaksSDP()) {
+				e.Color = "red"
+			}
+		}
+	}
+
+	if err := format.Write(graph); err != nil {
+		fmt.Fprintf(os.Stderr, "error building graph: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
+
+// newFormat creates the graph writer for -type.
+func (cmd *Command) newFormat(label *template.Template) (Format, error) {
 	var format Format
 	switch strings.ToLower(cmd.outputType) {
 	case "dot":
@@ -133,43 +357,45 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 			nocolor: cmd.nocolor,
 		}
 	default:
-		fmt.Fprintf(os.Stderr, "unknown output type %q\n", cmd.outputType)
-		return subcommands.ExitFailure
+		return nil, fmt.Errorf("unknown output type %q", cmd.outputType)
 	}
+	return format, nil
+}
 
-	if !cmd.printStandard {
-		go pkgset.LoadStd()
+// focusGraph restricts graph to the neighborhood of the -focus packages.
+func (cmd *Command) focusGraph(ctx context.Context, graph *pkggraph.Graph) (*pkggraph.Graph, error) {
+	dir, err := pkggraph.ParseDirection(cmd.direction)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := pkgset.Calc(ctx, f.Args())
+	target, err := pkgset.Calc(ctx, []string{cmd.focus})
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		return subcommands.ExitFailure
-	}
-	if !cmd.printStandard {
-		result = pkgset.Subtract(result, pkgset.Std())
+		return nil, fmt.Errorf("failed to evaluate focus expression %q: %w", cmd.focus, err)
 	}
 
-	graph := pkggraph.From(result)
-	for _, color := range cmd.colors {
-		target, err := pkgset.Calc(ctx, []string{color.Expr})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to evaluate color expression %q: %v", color.Expr, err)
-			continue
-		}
-		for id := range target {
-			if n, ok := graph.Packages[id]; ok {
-				n.Color = color.Color
-			}
+	var roots []*pkggraph.Node
+	for _, id := range target.IDs() {
+		if n, ok := graph.Packages[id]; ok {
+			roots = append(roots, n)
 		}
 	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("focus expression %q does not match any package in the graph", cmd.focus)
+	}
 
-	if err := format.Write(graph); err != nil {
-		fmt.Fprintf(os.Stderr, "error building graph: %v\n", err)
-		return subcommands.ExitFailure
+	if cmd.dim {
+		dist := graph.Neighborhood(roots, cmd.radius, dir)
+		for _, n := range graph.Sorted {
+			// Keep the colors assigned by -color.
+			if _, ok := dist[n]; !ok && n.Color == "" {
+				n.Color = "lightgray"
+			}
+		}
+		return graph, nil
 	}
 
-	return subcommands.ExitSuccess
+	return graph.Focus(roots, cmd.radius, dir), nil
 }
 
 type Format interface {
@@ -182,6 +408,33 @@ func pkgID(p *pkggraph.Node) string {
 	return strconv.Quote(p.ID)
 }
 
+func stubLabel(p *pkggraph.Node) string {
+	return fmt.Sprintf("+%d more", p.Stub)
+}
+
+// edgeLabel returns the label for an edge, empty when it doesn't need one.
+func edgeLabel(e *pkggraph.Edge) string {
+	var parts []string
+	if e.Weight > 1 {
+		parts = append(parts, strconv.Itoa(e.Weight))
+	}
+	if e.Hidden > 0 {
+		parts = append(parts, fmt.Sprintf("via %d hidden", e.Hidden))
+	}
+	if e.Structural() {
+		parts = append(parts, "implements")
+	}
+	if e.CoChanges > 0 {
+		parts = append(parts, fmt.Sprintf("co-changed %d", e.CoChanges))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// edgeDashed returns whether the edge should be drawn with a dashed line.
+func edgeDashed(e *pkggraph.Edge) bool {
+	return e.Hidden > 0 || e.TestOnly() || e.Structural() || e.CoChangeOnly()
+}
+
 // exprColors allows to define coloring for the given package set.
 type exprColors []exprColor
 
@@ -0,0 +1,243 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/templates"
+)
+
+type DiffCommand struct {
+	Command
+}
+
+func (*DiffCommand) Name() string     { return "graph-diff" }
+func (*DiffCommand) Synopsis() string { return "Print dependency graph changes between git revisions." }
+func (*DiffCommand) Usage() string {
+	return `graph-diff <rev-a> <rev-b> <expr>:
+	Print dependency graph changes between two revisions of the local git repository.
+
+	Both revisions are checked out into temporary git worktrees and
+	expr is evaluated in each of them. Added packages and edges are
+	colored green and removed ones red.
+
+Supported output types:
+
+	text - list of added (+) and removed (-) packages and edges
+
+	json - added and removed packages and edges as JSON
+
+	dot, graphml, tgf, edges, digraph, mermaid - see "help graph"
+
+	See "help expr" for further information about expressions.
+	See "help format" for further information about formatting.
+`
+}
+
+func (cmd *DiffCommand) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.nocolor, "nocolor", false, "disable coloring of unchanged packages")
+
+	f.StringVar(&cmd.docs, "docs", "https://pkg.go.dev/", "override the docs url to use")
+
+	f.StringVar(&cmd.outputType, "type", "dot", "output type (text, json, dot, graphml, digraph, edges, tgf, mermaid)")
+	f.StringVar(&cmd.labelFormat, "f", "", "label formatting")
+
+	f.BoolVar(&cmd.clusters, "cluster", false, "create clusters")
+	f.BoolVar(&cmd.shortID, "short", false, "use short package id-s inside clusters")
+	f.BoolVar(&cmd.hiddenEdges, "hidden", false, "add dashed edges for dependencies through excluded packages")
+
+	f.StringVar(&cmd.collapse, "collapse", "", "collapse packages into groups (module, dir=N, regexp)")
+}
+
+func (cmd *DiffCommand) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if f.NArg() < 3 {
+		fmt.Fprintln(os.Stderr, "expected <rev-a> <rev-b> <expr>")
+		return subcommands.ExitUsageError
+	}
+	revA, revB, expr := f.Arg(0), f.Arg(1), f.Args()[2:]
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	old, err := cmd.graphAt(ctx, revA, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	new, err := cmd.graphAt(ctx, revB, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	if cmd.collapse != "" {
+		group, err := pkggraph.ParseGroup(cmd.collapse)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		old, new = old.Collapse(group), new.Collapse(group)
+	}
+
+	diff := pkggraph.Diff(old, new)
+
+	switch strings.ToLower(cmd.outputType) {
+	case "text":
+		writeDiffText(os.Stdout, diff)
+		return subcommands.ExitSuccess
+	case "json":
+		if err := writeDiffJSON(os.Stdout, revA, revB, diff); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
+	if cmd.labelFormat == "" {
+		switch cmd.outputType {
+		case "dot":
+			cmd.labelFormat = `{{.ID}}\l{{ .Stat.Go.Lines }} / {{ .Stat.Go.Size }}\l`
+		default:
+			cmd.labelFormat = `{{.ID}}`
+		}
+	}
+
+	label, err := templates.Parse(cmd.labelFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid label format: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	format, err := cmd.newFormat(label)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	colorDiff(diff, cmd.nocolor)
+
+	if err := format.Write(diff); err != nil {
+		fmt.Fprintf(os.Stderr, "error building graph: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
+
+// graphAt builds the graph for expr at revision rev.
+func (cmd *DiffCommand) graphAt(ctx context.Context, rev string, expr []string) (*pkggraph.Graph, error) {
+	worktree, err := git.AddWorktree(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = worktree.Remove(ctx) }()
+
+	result, err := pkgset.CalcWithOpts(ctx, expr, pkgset.CalcOpts{
+		Dir: worktree.Dir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rev, err)
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	return pkggraph.FromWithOpts(result, pkggraph.FromOpts{
+		HiddenEdges: cmd.hiddenEdges,
+	}), nil
+}
+
+// colorDiff colors added nodes and edges green and removed ones red.
+// Unchanged edges are gray, unless nocolor is set.
+func colorDiff(diff *pkggraph.Graph, nocolor bool) {
+	colorOf := func(change pkggraph.Change) string {
+		switch change {
+		case pkggraph.Added:
+			return "green"
+		case pkggraph.Removed:
+			return "red"
+		default:
+			return ""
+		}
+	}
+	for _, n := range diff.Sorted {
+		if color := colorOf(n.Change); color != "" {
+			n.Color = color
+		}
+		for _, e := range n.Edges {
+			if color := colorOf(e.Change); color != "" {
+				e.Color = color
+			} else if !nocolor {
+				// Avoid unchanged edges looking added or removed.
+				e.Color = "gray"
+			}
+		}
+	}
+}
+
+func writeDiffText(w io.Writer, diff *pkggraph.Graph) {
+	sign := map[pkggraph.Change]string{pkggraph.Added: "+", pkggraph.Removed: "-"}
+	for _, n := range diff.Sorted {
+		if n.Change != pkggraph.Unchanged {
+			fmt.Fprintf(w, "%s %s\n", sign[n.Change], n.ID)
+		}
+	}
+	for _, n := range diff.Sorted {
+		for _, e := range n.Edges {
+			if e.Change != pkggraph.Unchanged {
+				fmt.Fprintf(w, "%s %s -> %s\n", sign[e.Change], n.ID, e.To.ID)
+			}
+		}
+	}
+}
+
+type diffEdge struct {
+	From string
+	To   string
+}
+
+type diffReport struct {
+	Old string
+	New string
+
+	AddedPackages   []string   `json:",omitempty"`
+	RemovedPackages []string   `json:",omitempty"`
+	AddedEdges      []diffEdge `json:",omitempty"`
+	RemovedEdges    []diffEdge `json:",omitempty"`
+}
+
+func writeDiffJSON(w io.Writer, revA, revB string, diff *pkggraph.Graph) error {
+	report := diffReport{Old: revA, New: revB}
+	for _, n := range diff.Sorted {
+		switch n.Change {
+		case pkggraph.Added:
+			report.AddedPackages = append(report.AddedPackages, n.ID)
+		case pkggraph.Removed:
+			report.RemovedPackages = append(report.RemovedPackages, n.ID)
+		}
+		for _, e := range n.Edges {
+			switch e.Change {
+			case pkggraph.Added:
+				report.AddedEdges = append(report.AddedEdges, diffEdge{From: n.ID, To: e.To.ID})
+			case pkggraph.Removed:
+				report.RemovedEdges = append(report.RemovedEdges, diffEdge{From: n.ID, To: e.To.ID})
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(report)
+}
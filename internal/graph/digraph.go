@@ -16,6 +16,10 @@ type Digraph struct {
 }
 
 func (ctx *Digraph) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -31,7 +35,8 @@ func (ctx *Digraph) Write(graph *pkggraph.Graph) error {
 	}
 	for _, node := range graph.Sorted {
 		fmt.Fprintf(ctx.out, "%s", labelCache[node])
-		for _, imp := range node.ImportsNodes {
+		for _, e := range node.Edges {
+			imp := e.To
 			fmt.Fprintf(ctx.out, " %s", labelCache[imp])
 		}
 		fmt.Fprintf(ctx.out, "\n")
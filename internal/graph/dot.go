@@ -25,6 +25,10 @@ type Dot struct {
 }
 
 func (ctx *Dot) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -116,12 +120,17 @@ func (ctx *Dot) WriteRegular(graph *pkggraph.Graph) error {
 	defer fmt.Fprintf(ctx.out, "}\n")
 
 	for _, n := range graph.Sorted {
+		if n.Stub > 0 {
+			fmt.Fprintf(ctx.out, "    %v [label=\"%v\" style=dashed %v];\n", pkgID(n), ctx.Label(n), ctx.colorOf(n))
+			continue
+		}
 		fmt.Fprintf(ctx.out, "    %v [label=\"%v\" %v %v];\n", pkgID(n), ctx.Label(n), ctx.Ref(n), ctx.colorOf(n))
 	}
 
 	for _, src := range graph.Sorted {
-		for _, dst := range src.ImportsNodes {
-			fmt.Fprintf(ctx.out, "    %v -> %v [%v];\n", pkgID(src), pkgID(dst), ctx.colorOf(dst))
+		for _, e := range src.Edges {
+			dst := e.To
+			fmt.Fprintf(ctx.out, "    %v -> %v [%v%v];\n", pkgID(src), pkgID(dst), ctx.edgeColorOf(e), ctx.edgeAttrs(e))
 		}
 	}
 
@@ -175,6 +184,8 @@ func (ctx *Dot) WriteClusters(graph *pkggraph.Graph) error {
 					shape = "point"
 				}
 				fmt.Fprintf(ctx.out, "    %v [label=\"\" tooltip=\"%v\" shape=%v %v rank=0];\n", pkgID(gn), tn.Path(), shape, ctx.colorOf(gn))
+			} else if gn.Stub > 0 {
+				fmt.Fprintf(ctx.out, "    %v [label=\"%v\" style=dashed %v];\n", pkgID(gn), ctx.Label(gn), ctx.colorOf(gn))
 			} else {
 				label := ctx.TreePackageLabel(tn, printed[tn.Parent])
 				href := ctx.TreePackageRef(tn)
@@ -188,15 +199,16 @@ func (ctx *Dot) WriteClusters(graph *pkggraph.Graph) error {
 
 	for _, src := range graph.Sorted {
 		srctree := lookup[src]
-		for _, dst := range src.ImportsNodes {
+		for _, e := range src.Edges {
+			dst := e.To
 			dstID := pkgID(dst)
 			dstTree := lookup[dst]
 			tooltip := src.ID + " -> " + dst.ID
 
 			if isCluster[dst] && srctree.Parent != dstTree {
-				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" lhead=%q %v];\n", pkgID(src), dstID, tooltip, "cluster_"+dst.ID, ctx.colorOf(dst))
+				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" lhead=%q %v%v];\n", pkgID(src), dstID, tooltip, "cluster_"+dst.ID, ctx.edgeColorOf(e), ctx.edgeAttrs(e))
 			} else {
-				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" %v];\n", pkgID(src), dstID, tooltip, ctx.colorOf(dst))
+				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" %v%v];\n", pkgID(src), dstID, tooltip, ctx.edgeColorOf(e), ctx.edgeAttrs(e))
 			}
 		}
 	}
@@ -204,11 +216,36 @@ func (ctx *Dot) WriteClusters(graph *pkggraph.Graph) error {
 	return nil
 }
 
+// edgeAttrs returns additional attributes for the edge, prefixed with a space.
+func (ctx *Dot) edgeAttrs(e *pkggraph.Edge) string {
+	var attrs []string
+	if label := edgeLabel(e); label != "" {
+		attrs = append(attrs, "label="+strconv.Quote(label))
+	}
+	if edgeDashed(e) {
+		attrs = append(attrs, "style=dashed")
+	}
+	if e.Blank() {
+		attrs = append(attrs, "arrowhead=odot")
+	}
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " " + strings.Join(attrs, " ")
+}
+
+func (ctx *Dot) edgeColorOf(e *pkggraph.Edge) string {
+	if e.Color != "" {
+		return "color=" + strconv.Quote(e.Color)
+	}
+	return ctx.colorOf(e.To)
+}
+
 func (ctx *Dot) colorOf(p *pkggraph.Node) string {
 	if p.Color != "" {
 		return "color=" + strconv.Quote(p.Color)
 	}
-	if ctx.nocolor {
+	if ctx.nocolor || p.Stub > 0 {
 		return ""
 	}
 
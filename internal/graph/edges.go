@@ -16,6 +16,10 @@ type Edges struct {
 }
 
 func (ctx *Edges) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -30,7 +34,8 @@ func (ctx *Edges) Write(graph *pkggraph.Graph) error {
 		labelCache[node] = ctx.Label(node)
 	}
 	for _, node := range graph.Sorted {
-		for _, imp := range node.ImportsNodes {
+		for _, e := range node.Edges {
+			imp := e.To
 			fmt.Fprintf(ctx.out, "%s %s\n", labelCache[node], labelCache[imp])
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -24,6 +25,10 @@ type GraphML struct {
 }
 
 func (ctx *GraphML) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -39,6 +44,15 @@ func (ctx *GraphML) Write(graph *pkggraph.Graph) error {
 	file.Key = []graphml.Key{
 		{For: "node", ID: "label", AttrName: "label", AttrType: "string"},
 		{For: "node", ID: "module", AttrName: "module", AttrType: "string"},
+		{For: "edge", ID: "weight", AttrName: "weight", AttrType: "int"},
+		{For: "edge", ID: "hidden", AttrName: "hidden", AttrType: "int"},
+		{For: "edge", ID: "files", AttrName: "files", AttrType: "string"},
+		{For: "edge", ID: "testonly", AttrName: "testonly", AttrType: "boolean"},
+		{For: "edge", ID: "blank", AttrName: "blank", AttrType: "boolean"},
+		{For: "edge", ID: "dot", AttrName: "dot", AttrType: "boolean"},
+		{For: "edge", ID: "aliased", AttrName: "aliased", AttrType: "boolean"},
+		{For: "edge", ID: "constraints", AttrName: "constraints", AttrType: "string"},
+		{For: "edge", ID: "implements", AttrName: "implements", AttrType: "string"},
 		{For: "node", ID: "ynodelabel", YFilesType: "nodegraphics"},
 		{For: "edge", ID: "yedgelabel", YFilesType: "edgegraphics"},
 	}
@@ -72,12 +86,34 @@ func (ctx *GraphML) ConvertGraph(graph *pkggraph.Graph) *graphml.Graph {
 		ctx.addYedLabelAttr(&outnode.Attrs, "ynodelabel", label, node)
 		out.Node = append(out.Node, outnode)
 
-		for _, imp := range node.ImportsNodes {
+		for _, e := range node.Edges {
+			imp := e.To
 			edge := graphml.Edge{
 				Source: node.ID,
 				Target: imp.ID,
 			}
-			ctx.addYedEdgeAttr(&edge.Attrs, "yedgelabel", label, imp)
+			if e.Weight > 1 {
+				edge.Attrs.AddNonEmpty("weight", strconv.Itoa(e.Weight))
+			}
+			if e.Hidden > 0 {
+				edge.Attrs.AddNonEmpty("hidden", strconv.Itoa(e.Hidden))
+			}
+			if len(e.Imports) > 0 {
+				edge.Attrs.AddNonEmpty("files", strings.Join(e.Files(), " "))
+				edge.Attrs.AddNonEmpty("testonly", strconv.FormatBool(e.TestOnly()))
+				edge.Attrs.AddNonEmpty("blank", strconv.FormatBool(e.Blank()))
+				edge.Attrs.AddNonEmpty("dot", strconv.FormatBool(e.Dot()))
+				edge.Attrs.AddNonEmpty("aliased", strconv.FormatBool(e.Aliased()))
+				edge.Attrs.AddNonEmpty("constraints", strings.Join(e.Constraints(), "; "))
+			}
+			if len(e.Implementations) > 0 {
+				var impls []string
+				for _, impl := range e.Implementations {
+					impls = append(impls, impl.TypeString()+" "+impl.Interface.Name())
+				}
+				edge.Attrs.AddNonEmpty("implements", strings.Join(impls, "; "))
+			}
+			ctx.addYedEdgeAttr(&edge.Attrs, "yedgelabel", label, e)
 			out.Edge = append(out.Edge, edge)
 		}
 	}
@@ -102,24 +138,39 @@ func (ctx *GraphML) addYedLabelAttr(attrs *graphml.Attrs, key, value string, nod
 	*attrs = append(*attrs, graphml.Attr{Key: key, Value: buf.Bytes()})
 }
 
-func (ctx *GraphML) addYedEdgeAttr(attrs *graphml.Attrs, key, value string, node *pkggraph.Node) {
+func (ctx *GraphML) addYedEdgeAttr(attrs *graphml.Attrs, key, value string, e *pkggraph.Edge) {
 	if value == "" {
 		return
 	}
+	lineType := "line"
+	if edgeDashed(e) {
+		lineType = "dashed"
+	}
 	var buf bytes.Buffer
 	buf.WriteString(`<y:PolyLineEdge>`)
-	fmt.Fprintf(&buf, `<y:LineStyle color="%v" type="line" width="1.0" />`, ctx.colorOf(node))
+	fmt.Fprintf(&buf, `<y:LineStyle color="%v" type="%v" width="1.0" />`, ctx.edgeColorOf(e), lineType)
+	if label := edgeLabel(e); label != "" {
+		buf.WriteString(`<y:EdgeLabel>`)
+		if err := xml.EscapeText(&buf, []byte(label)); err != nil {
+			// this shouldn't ever happen
+			panic(err)
+		}
+		buf.WriteString(`</y:EdgeLabel>`)
+	}
 	buf.WriteString(`</y:PolyLineEdge>`)
 	*attrs = append(*attrs, graphml.Attr{Key: key, Value: buf.Bytes()})
 }
 
+func (ctx *GraphML) edgeColorOf(e *pkggraph.Edge) string {
+	if e.Color != "" {
+		return namedColorHex(e.Color)
+	}
+	return ctx.colorOf(e.To)
+}
+
 func (ctx *GraphML) colorOf(p *pkggraph.Node) string {
 	if p.Color != "" {
-		c, ok := colornames.Map[strings.ToLower(p.Color)]
-		if ok {
-			return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
-		}
-		return p.Color
+		return namedColorHex(p.Color)
 	}
 	if ctx.nocolor {
 		return ""
@@ -129,3 +180,12 @@ func (ctx *GraphML) colorOf(p *pkggraph.Node) string {
 	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
 	return hslhex(hue, 0.6, 0.6)
 }
+
+// namedColorHex converts a color name to hex, other colors are returned as is.
+func namedColorHex(color string) string {
+	c, ok := colornames.Map[strings.ToLower(color)]
+	if ok {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return color
+}
@@ -23,6 +23,10 @@ type Mermaid struct {
 }
 
 func (ctx *Mermaid) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -58,7 +62,7 @@ func (ctx *Mermaid) WriteRegular(graph *pkggraph.Graph) error {
 		nid := ctx.PkgID(n)
 		fmt.Fprintf(ctx.out, "    %v[%q]\n", nid, ctx.Label(n))
 
-		if ref := ctx.Ref(n); ref != "" {
+		if ref := ctx.Ref(n); ref != "" && n.Stub == 0 {
 			fmt.Fprintf(ctx.out, "    click %v %q _blank\n", nid, ref)
 		}
 
@@ -70,10 +74,11 @@ func (ctx *Mermaid) WriteRegular(graph *pkggraph.Graph) error {
 	linkIndex := 0
 	for _, src := range graph.Sorted {
 		srcid := ctx.PkgID(src)
-		for _, dst := range src.ImportsNodes {
+		for _, e := range src.Edges {
+			dst := e.To
 			dstid := ctx.PkgID(dst)
-			fmt.Fprintf(ctx.out, "    %v --> %v\n", srcid, dstid)
-			if color := ctx.strokeColorOf(dst); color != "" {
+			fmt.Fprintf(ctx.out, "    %v %v %v\n", srcid, ctx.arrow(e), dstid)
+			if color := ctx.edgeColorOf(e); color != "" {
 				fmt.Fprintf(ctx.out, "    linkStyle %v stroke:%v\n", linkIndex, color)
 			}
 			linkIndex++
@@ -83,6 +88,18 @@ func (ctx *Mermaid) WriteRegular(graph *pkggraph.Graph) error {
 	return nil
 }
 
+// arrow returns the link between nodes for the edge.
+func (ctx *Mermaid) arrow(e *pkggraph.Edge) string {
+	arrow := "-->"
+	if edgeDashed(e) {
+		arrow = "-.->"
+	}
+	if label := edgeLabel(e); label != "" {
+		arrow += "|" + label + "|"
+	}
+	return arrow
+}
+
 func (ctx *Mermaid) colorOf(p *pkggraph.Node) string {
 	if p.Color != "" {
 		return p.Color
@@ -96,6 +113,13 @@ func (ctx *Mermaid) colorOf(p *pkggraph.Node) string {
 	return hslahex(hue, 0.6, 0.7, 0.6)
 }
 
+func (ctx *Mermaid) edgeColorOf(e *pkggraph.Edge) string {
+	if e.Color != "" {
+		return e.Color
+	}
+	return ctx.strokeColorOf(e.To)
+}
+
 func (ctx *Mermaid) strokeColorOf(p *pkggraph.Node) string {
 	if p.Color != "" {
 		return p.Color
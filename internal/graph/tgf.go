@@ -16,6 +16,10 @@ type TGF struct {
 }
 
 func (ctx *TGF) Label(p *pkggraph.Node) string {
+	if p.Stub > 0 {
+		return stubLabel(p)
+	}
+
 	var labelText strings.Builder
 	err := ctx.label.Execute(&labelText, p)
 	if err != nil {
@@ -35,8 +39,12 @@ func (ctx *TGF) Write(graph *pkggraph.Graph) error {
 	fmt.Fprintf(ctx.out, "#\n")
 
 	for _, node := range graph.Sorted {
-		for _, imp := range node.ImportsNodes {
-			fmt.Fprintf(ctx.out, "%d %d\n", indexCache[node], indexCache[imp])
+		for _, e := range node.Edges {
+			if label := edgeLabel(e); label != "" {
+				fmt.Fprintf(ctx.out, "%d %d %s\n", indexCache[node], indexCache[e.To], label)
+			} else {
+				fmt.Fprintf(ctx.out, "%d %d\n", indexCache[node], indexCache[e.To])
+			}
 		}
 	}
 
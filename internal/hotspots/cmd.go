@@ -0,0 +1,122 @@
+package hotspots
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+type Command struct {
+	printStandard bool
+
+	days  int
+	limit int
+
+	output  string
+	noAlign bool
+	plot    string
+}
+
+func (*Command) Name() string     { return "hotspots" }
+func (*Command) Synopsis() string { return "Rank stable packages that change frequently." }
+func (*Command) Usage() string {
+	return `hotspots <expr>:
+	Rank packages by how often they change in the local git history
+	combined with how many packages depend on them.
+
+	Columns:
+	  Score    Commits × Ca × (1 - I): high for stable packages with
+	           many importers that change frequently, where changes
+	           affect many dependents. Packages without importers
+	           score zero.
+	  Commits  Commits in the last -days days changing the Go files
+	           of the package.
+	  Lines    Added and deleted lines in those commits.
+	  Ca, Ce, I, D are Robert Martin's metrics, see "help metrics".
+
+	-plot svg writes a scatter plot of instability vs commits instead,
+	with points sized by lines changed. Hotspots are red and packages
+	without importers gray.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+
+	f.IntVar(&cmd.days, "days", 90, "consider commits from the last N days, 0 for all")
+	f.IntVar(&cmd.limit, "n", 0, "print only the top N packages, 0 for all")
+
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json)")
+	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
+	f.StringVar(&cmd.plot, "plot", "", "plot instability vs commits instead of the table (svg)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected text or json\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+	if cmd.plot != "" && cmd.plot != "svg" {
+		fmt.Fprintf(os.Stderr, "unknown plot format %q, expected svg\n", cmd.plot)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	allPkgs := result
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.From(result)
+	graph.ComputeMetrics(allPkgs)
+
+	opts := git.LogOpts{Lines: true}
+	if cmd.days > 0 {
+		opts.Since = strconv.Itoa(cmd.days) + " days ago"
+	}
+	commits, err := git.Log(ctx, ".", opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	hotspots := Rank(graph.Sorted, ChurnOf(graph.Sorted, commits))
+	if cmd.limit > 0 && len(hotspots) > cmd.limit {
+		hotspots = hotspots[:cmd.limit]
+	}
+
+	switch {
+	case cmd.plot != "":
+		err = WritePlot(os.Stdout, hotspots)
+	case output == "json":
+		err = WriteJSON(os.Stdout, hotspots)
+	default:
+		err = WriteText(os.Stdout, hotspots, cmd.noAlign)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
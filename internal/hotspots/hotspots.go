@@ -0,0 +1,129 @@
+// Package hotspots combines the churn of packages with their metrics.
+package hotspots
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+// Churn is how much a package changed.
+type Churn struct {
+	// Commits is the number of commits changing the package.
+	Commits int
+	// Lines is the number of added and deleted lines.
+	Lines int
+}
+
+// ChurnOf computes the churn of the nodes from commits,
+// mapping the changed files to packages via GoFiles.
+func ChurnOf(nodes []*pkggraph.Node, commits []git.Commit) map[string]Churn {
+	byFile := map[string][]string{}
+	for _, n := range nodes {
+		for _, file := range n.GoFiles {
+			file = filepath.Clean(file)
+			byFile[file] = append(byFile[file], n.ID)
+		}
+	}
+
+	churn := map[string]Churn{}
+	for _, commit := range commits {
+		changed := map[string]bool{}
+		for _, file := range commit.Files {
+			for _, id := range byFile[filepath.Clean(file.Path)] {
+				c := churn[id]
+				c.Lines += file.Added + file.Deleted
+				churn[id] = c
+				changed[id] = true
+			}
+		}
+		for id := range changed {
+			c := churn[id]
+			c.Commits++
+			churn[id] = c
+		}
+	}
+	return churn
+}
+
+// Hotspot is a package with its churn and metrics.
+type Hotspot struct {
+	ID string
+	Churn
+
+	Ca float64
+	Ce float64
+	I  float64
+	D  float64
+
+	// Score is Commits × Ca × (1 - I), which is high for stable packages
+	// with many importers that change frequently. Packages without
+	// importers score zero.
+	Score float64
+}
+
+// Rank returns the hotspots of nodes with any churn,
+// sorted by Score, Lines and ID.
+func Rank(nodes []*pkggraph.Node, churn map[string]Churn) []Hotspot {
+	var hotspots []Hotspot
+	for _, n := range nodes {
+		c, ok := churn[n.ID]
+		if !ok || c.Commits == 0 {
+			continue
+		}
+		hotspots = append(hotspots, Hotspot{
+			ID:    n.ID,
+			Churn: c,
+			Ca:    n.Ca, Ce: n.Ce,
+			I: n.I, D: n.D,
+			Score: float64(c.Commits) * n.Ca * (1 - n.I),
+		})
+	}
+
+	sort.Slice(hotspots, func(i, k int) bool {
+		a, b := &hotspots[i], &hotspots[k]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Lines != b.Lines:
+			return a.Lines > b.Lines
+		default:
+			return a.ID < b.ID
+		}
+	})
+	return hotspots
+}
+
+// WriteText writes hotspots as a table.
+func WriteText(out io.Writer, hotspots []Hotspot, noAlign bool) error {
+	var w io.Writer = out
+	if !noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintln(w, "ID\tScore\tCommits\tLines\tCa\tCe\tI\tD")
+	for _, h := range hotspots {
+		fmt.Fprintf(w, "%s\t%.2f\t%d\t%d\t%v\t%v\t%.2f\t%.2f\n", h.ID, h.Score, h.Commits, h.Lines, h.Ca, h.Ce, h.I, h.D)
+	}
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+	return nil
+}
+
+// WriteJSON writes hotspots as a JSON array.
+func WriteJSON(w io.Writer, hotspots []Hotspot) error {
+	if hotspots == nil {
+		hotspots = []Hotspot{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(hotspots)
+}
@@ -0,0 +1,78 @@
+package hotspots
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+func TestRank(t *testing.T) {
+	core := &packages.Package{ID: "core", PkgPath: "core", GoFiles: []string{"/repo/core/core.go"}, Imports: map[string]*packages.Package{}}
+	app := &packages.Package{ID: "app", PkgPath: "app", GoFiles: []string{"/repo/app/main.go"}, Imports: map[string]*packages.Package{"core": core}}
+	idle := &packages.Package{ID: "idle", PkgPath: "idle", GoFiles: []string{"/repo/idle/idle.go"}, Imports: map[string]*packages.Package{}}
+	pkgs := map[string]*packages.Package{"core": core, "app": app, "idle": idle}
+
+	graph := pkggraph.From(pkgs)
+	graph.ComputeMetrics(pkgs)
+
+	commits := []git.Commit{
+		{Hash: "1", Files: []git.File{{Path: "/repo/core/core.go", Added: 10, Deleted: 2}, {Path: "/repo/app/main.go", Added: 1}}},
+		{Hash: "2", Files: []git.File{{Path: "/repo/core/core.go", Added: 3}}},
+		{Hash: "3", Files: []git.File{{Path: "/repo/app/main.go", Added: 5, Deleted: 5}, {Path: "/repo/README.md", Added: 1}}},
+	}
+
+	churn := ChurnOf(graph.Sorted, commits)
+	wantChurn := map[string]Churn{
+		"core": {Commits: 2, Lines: 15},
+		"app":  {Commits: 2, Lines: 11},
+	}
+	if !reflect.DeepEqual(churn, wantChurn) {
+		t.Errorf("got churn %+v, want %+v", churn, wantChurn)
+	}
+
+	hotspots := Rank(graph.Sorted, churn)
+	var ids []string
+	for _, h := range hotspots {
+		ids = append(ids, h.ID)
+	}
+	if want := []string{"core", "app"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if hotspots[0].Score != 2 || hotspots[1].Score != 0 {
+		t.Errorf("got scores %v and %v, want 2 and 0", hotspots[0].Score, hotspots[1].Score)
+	}
+}
+
+func TestTickStep(t *testing.T) {
+	for n, want := range map[int]int{1: 1, 10: 1, 11: 2, 45: 5, 100: 10, 101: 20, 2500: 500} {
+		if got := tickStep(n); got != want {
+			t.Errorf("tickStep(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestRankIsolated(t *testing.T) {
+	core := &packages.Package{ID: "core", PkgPath: "core", Imports: map[string]*packages.Package{}}
+	app := &packages.Package{ID: "app", PkgPath: "app", Imports: map[string]*packages.Package{"core": core}}
+	lone := &packages.Package{ID: "lone", PkgPath: "lone", Imports: map[string]*packages.Package{}}
+	pkgs := map[string]*packages.Package{"core": core, "app": app, "lone": lone}
+
+	graph := pkggraph.From(pkgs)
+	graph.ComputeMetrics(pkgs)
+
+	// lone has I=0 like core, but nothing depends on it.
+	hotspots := Rank(graph.Sorted, map[string]Churn{
+		"core": {Commits: 1, Lines: 1},
+		"lone": {Commits: 10, Lines: 100},
+	})
+	if len(hotspots) != 2 || hotspots[0].ID != "core" || hotspots[1].ID != "lone" {
+		t.Fatalf("got %+v, want core before lone", hotspots)
+	}
+	if hotspots[1].Score != 0 {
+		t.Errorf("got score %v for an isolated package, want 0", hotspots[1].Score)
+	}
+}
@@ -0,0 +1,105 @@
+package hotspots
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	plotSize   = 600.0
+	plotMargin = 60.0
+
+	// hotInstability is the instability below which frequently changed
+	// packages are considered hotspots in the plot.
+	hotInstability = 0.3
+
+	// The colors of hotspots, packages without importers, which score
+	// zero regardless of their churn, and the other packages.
+	hotColor        = "#e15759"
+	unimportedColor = "#bab0ac"
+	defaultColor    = "#4477aa"
+)
+
+// WritePlot writes an SVG scatter plot of instability vs commits,
+// with points sized by lines changed. The stable and frequently
+// changed corner is highlighted and packages without importers are
+// shaded separately, since they score zero.
+func WritePlot(w io.Writer, hotspots []Hotspot) error {
+	maxCommits, maxLines := 1, 1
+	for _, h := range hotspots {
+		maxCommits = max(maxCommits, h.Commits)
+		maxLines = max(maxLines, h.Lines)
+	}
+	step := tickStep(maxCommits)
+	top := int(math.Ceil(float64(maxCommits)/float64(step))) * step
+
+	px := func(i float64) float64 { return plotMargin + i*plotSize }
+	py := func(commits float64) float64 { return plotMargin + (1-commits/float64(top))*plotSize }
+
+	size := plotSize + 2*plotMargin
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\" font-family=\"sans-serif\" font-size=\"12\">\n", size, size, size, size)
+	fmt.Fprintf(w, "<rect width=\"%.0f\" height=\"%.0f\" fill=\"white\"/>\n", size, size)
+
+	// Stable packages in the upper half of the commits.
+	fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\" fill-opacity=\"0.15\"/>\n",
+		px(0), py(float64(top)), hotInstability*plotSize, plotSize/2, hotColor)
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" fill=\"#b03030\">hotspots</text>\n", px(0.02), py(float64(top))+16)
+
+	fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"none\" stroke=\"#333\"/>\n", px(0), py(float64(top)), plotSize, plotSize)
+	for _, tick := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%.2f</text>\n", px(tick), py(0)+18, tick)
+	}
+	for commits := 0; commits <= top; commits += step {
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%d</text>\n", px(0)-6, py(float64(commits))+4, commits)
+	}
+	for i, entry := range []struct{ color, label string }{
+		{hotColor, "hotspot"},
+		{unimportedColor, "no importers (score 0)"},
+		{defaultColor, "other"},
+	} {
+		y := py(float64(top)) + 16 + float64(i)*16
+		fmt.Fprintf(w, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"5\" fill=\"%s\" fill-opacity=\"0.7\" stroke=\"#333\" stroke-width=\"0.5\"/>\n", px(1)-150, y-4, entry.color)
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\">%s</text>\n", px(1)-140, y, entry.label)
+	}
+
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">Instability (I)</text>\n", px(0.5), py(0)+40)
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\" transform=\"rotate(-90 %.1f %.1f)\">Commits</text>\n", px(0)-42, py(float64(top)/2), px(0)-42, py(float64(top)/2))
+
+	// Larger points first so that smaller ones remain visible.
+	sorted := append([]Hotspot(nil), hotspots...)
+	sort.SliceStable(sorted, func(i, k int) bool { return sorted[i].Lines > sorted[k].Lines })
+	for _, h := range sorted {
+		radius := 3 + 17*math.Sqrt(float64(h.Lines)/float64(maxLines))
+		color := defaultColor
+		switch {
+		case h.Ca == 0:
+			color = unimportedColor
+		case h.I < hotInstability && h.Commits*2 >= top:
+			color = hotColor
+		}
+		tip := html.EscapeString(fmt.Sprintf("%s\ncommits=%d lines=%d\nCa=%v Ce=%v I=%.2f\nscore=%.2f",
+			h.ID, h.Commits, h.Lines, h.Ca, h.Ce, h.I, h.Score))
+		fmt.Fprintf(w, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"%s\" fill-opacity=\"0.7\" stroke=\"#333\" stroke-width=\"0.5\"><title>%s</title></circle>\n",
+			px(h.I), py(float64(h.Commits)), radius, color, tip)
+	}
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// tickStep returns a step of 1, 2 or 5 times a power of ten,
+// such that there are at most 10 ticks up to n.
+func tickStep(n int) int {
+	step := 1
+	for {
+		for _, mul := range []int{1, 2, 5} {
+			if n <= step*mul*10 {
+				return step * mul
+			}
+		}
+		step *= 10
+	}
+}
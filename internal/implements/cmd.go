@@ -0,0 +1,94 @@
+package implements
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+type Command struct {
+	printStandard bool
+	progress      bool
+	constraints   bool
+	output        string
+}
+
+func (*Command) Name() string { return "implements" }
+func (*Command) Synopsis() string {
+	return "List types satisfying interfaces of packages they are not connected to."
+}
+func (*Command) Usage() string {
+	return `implements <expr>:
+	List concrete types that satisfy interfaces declared in another package,
+	when neither package imports the other.
+
+	These pairs are the structural couplings counted by "metrics -types"
+	as SCa and SCe. The output is grouped by package pair, types that
+	implement the interface only via pointer receivers are prefixed with "*".
+
+	Generic types and interfaces match when some instantiation satisfies
+	the interface. Constraint interfaces with type sets, such as
+	interface{ ~int; String() string }, are ignored unless -constraints
+	is used, in which case they are listed separately.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.progress, "progress", false, "report progress to stderr")
+	f.BoolVar(&cmd.constraints, "constraints", false, "include constraint interfaces with type sets")
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q, expected text or json\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.CalcWithOpts(ctx, f.Args(), pkgset.CalcOpts{
+		TypesMode: true,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.From(result)
+	opts := pkggraph.StructuralOpts{
+		Constraints: cmd.constraints,
+	}
+	if cmd.progress {
+		opts.Progress = pkggraph.ProgressTo(os.Stderr)
+	}
+	pairs := Pairs(graph.FindImplementationsWithOpts(opts))
+
+	if output == "json" {
+		if err := WriteJSON(os.Stdout, pairs); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
+	WriteText(os.Stdout, pairs)
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,85 @@
+package implements
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+// Pair contains the implementations between two packages without an import edge.
+type Pair struct {
+	// From is the package with the concrete types.
+	From string
+	// To is the package with the interfaces.
+	To string
+
+	Implementations []Implementation
+}
+
+// Implementation is a concrete type satisfying an interface.
+type Implementation struct {
+	Type      string
+	Interface string
+	// Pointer is set when only the pointer to Type implements Interface.
+	Pointer bool `json:",omitempty"`
+	// TypeSet is set when Interface is a constraint with a type set.
+	TypeSet bool `json:",omitempty"`
+}
+
+// Pairs groups the implicit implementations by package pair.
+// impls must be sorted by packages, as returned by Graph.FindImplementations.
+func Pairs(impls []pkggraph.Implementation) []Pair {
+	var pairs []Pair
+	for _, impl := range impls {
+		if !impl.Implicit() {
+			continue
+		}
+		if len(pairs) == 0 || pairs[len(pairs)-1].From != impl.From.ID || pairs[len(pairs)-1].To != impl.To.ID {
+			pairs = append(pairs, Pair{From: impl.From.ID, To: impl.To.ID})
+		}
+		last := &pairs[len(pairs)-1]
+		last.Implementations = append(last.Implementations, Implementation{
+			Type:      impl.TypeName(),
+			Interface: impl.Interface.Name(),
+			Pointer:   impl.Pointer,
+			TypeSet:   impl.TypeSet,
+		})
+	}
+	return pairs
+}
+
+// WriteText writes pairs as an indented list, where the implementations of
+// interfaces with type sets are listed after the method-only interfaces.
+func WriteText(w io.Writer, pairs []Pair) {
+	for _, pair := range pairs {
+		fmt.Fprintf(w, "%s -> %s\n", pair.From, pair.To)
+		for _, typeSet := range []bool{false, true} {
+			for _, impl := range pair.Implementations {
+				if impl.TypeSet != typeSet {
+					continue
+				}
+				typ := impl.Type
+				if impl.Pointer {
+					typ = "*" + typ
+				}
+				if impl.TypeSet {
+					fmt.Fprintf(w, "    %s satisfies constraint %s\n", typ, impl.Interface)
+				} else {
+					fmt.Fprintf(w, "    %s implements %s\n", typ, impl.Interface)
+				}
+			}
+		}
+	}
+}
+
+// WriteJSON writes pairs as a JSON array.
+func WriteJSON(w io.Writer, pairs []Pair) error {
+	if pairs == nil {
+		pairs = []Pair{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(pairs)
+}
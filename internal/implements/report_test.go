@@ -0,0 +1,44 @@
+package implements
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/testutil"
+)
+
+func TestPairs(t *testing.T) {
+	fset := token.NewFileSet()
+	base := testutil.CheckPackage(t, fset, "base", `package base
+type Reader interface{ Read([]byte) (int, error) }
+type Closer interface{ Close() error }
+`)
+	impl := testutil.CheckPackage(t, fset, "impl", `package impl
+type File struct{}
+func (File) Close() error { return nil }
+func (*File) Read([]byte) (int, error) { return 0, nil }
+type Other struct{}
+`)
+
+	graph := pkggraph.From(map[string]*packages.Package{"base": base, "impl": impl})
+	pairs := Pairs(graph.FindImplementations())
+
+	var out bytes.Buffer
+	WriteText(&out, pairs)
+
+	want := "impl -> base\n" +
+		"    File implements Closer\n" +
+		"    *File implements Reader\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	impl.Imports["base"] = base
+	if pairs := Pairs(graph.FindImplementations()); len(pairs) != 0 {
+		t.Errorf("expected no pairs between importing packages, got %v", pairs)
+	}
+}
@@ -11,15 +11,29 @@ import (
 
 	"github.com/google/subcommands"
 
+	"github.com/flamingoosesoftwareinc/goda/internal/coverage"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/predicate"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
 	"github.com/flamingoosesoftwareinc/goda/internal/templates"
 )
 
 type Command struct {
 	printStandard bool
+	hiddenEdges   bool
 	typesMode     bool
+	progress      bool
+	level         string
 
+	scope         string
+	excludeStd    bool
+	excludeVendor bool
+
+	coverProfile string
+	where        string
+
+	output  string
 	noAlign bool
 	header  string
 	format  string
@@ -31,6 +45,13 @@ func (*Command) Usage() string {
 	return `list <expr>:
 	List packages using an expression.
 
+	-coverprofile cover.out sets .Coverage to the percentage of covered
+	statements of each package, see "help format".
+
+	-where 'Ca > 5 && Coverage < 50' lists only the packages matching the
+	condition, which uses Go syntax and can refer to any field shown by
+	"help format".
+
 	See "help expr" for further information about expressions.
 	See "help format" for further information about formatting.
 `
@@ -39,7 +60,16 @@ func (*Command) Usage() string {
 func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 	f.BoolVar(&cmd.typesMode, "types", false, "enable structural coupling analysis (SCa/SCe)")
-
+	f.BoolVar(&cmd.progress, "progress", false, "report progress of the structural coupling analysis to stderr")
+	f.BoolVar(&cmd.hiddenEdges, "hidden", false, "add edges for dependencies through excluded packages")
+	f.StringVar(&cmd.scope, "scope", "", "count couplings only within packages matching the expression, \"analyzed\" for the printed packages")
+	f.BoolVar(&cmd.excludeStd, "exclude-std", false, "exclude std imports from Ce")
+	f.BoolVar(&cmd.excludeVendor, "exclude-vendor", false, "exclude vendored imports from Ce")
+	f.StringVar(&cmd.level, "level", "package", "compute metrics for groups of packages (package, module, dir=N, regexp)")
+	f.StringVar(&cmd.coverProfile, "coverprofile", "", "read test coverage from a \"go test -coverprofile\" file into .Coverage")
+	f.StringVar(&cmd.where, "where", "", "list only packages matching the condition, e.g. 'Ca > 5 && Coverage < 50'")
+
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json, ndjson, csv)")
 	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
 	f.StringVar(&cmd.header, "h", "", "header for the table\nautomatically derives from format, when empty, use \"-\" to skip")
 	f.StringVar(&cmd.format, "f", "{{.ID}}", "formatting")
@@ -52,12 +82,33 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
+	var where *predicate.Expr
+	if cmd.where != "" {
+		where, err = predicate.Parse(cmd.where)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+	}
+
+	var records record.Writer
+	if !record.IsText(cmd.output) {
+		records, err = record.NewWriter(cmd.output, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+	}
+
 	if !cmd.printStandard {
 		go pkgset.LoadStd()
 	}
 
+	// Records contain the cohesion metrics, the text output only when referred to.
+	cohesion := !record.IsText(cmd.output) || pkggraph.UsesCohesion(cmd.format, cmd.where)
 	result, err := pkgset.CalcWithOpts(ctx, f.Args(), pkgset.CalcOpts{
-		TypesMode: cmd.typesMode,
+		TypesMode:  cmd.typesMode,
+		SyntaxMode: cohesion,
 	})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -69,11 +120,79 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		result = pkgset.Subtract(result, pkgset.Std())
 	}
 
-	graph := pkggraph.From(result)
-	graph.ComputeMetrics(allPkgs)
+	graph := pkggraph.FromWithOpts(result, pkggraph.FromOpts{
+		HiddenEdges: cmd.hiddenEdges,
+	})
+
+	scope, err := pkggraph.ParseScope(ctx, cmd.scope, "", result)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	metricsOpts := pkggraph.MetricsOpts{
+		Scope:         scope,
+		ExcludeStd:    cmd.excludeStd,
+		ExcludeVendor: cmd.excludeVendor,
+	}
+	graph.ComputeMetricsWithOpts(allPkgs, metricsOpts)
+
+	if cmd.coverProfile != "" {
+		profile, err := coverage.Load(cmd.coverProfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		coverage.Apply(graph, profile)
+	}
 
 	if cmd.typesMode {
-		graph.ComputeStructuralCoupling()
+		graph.ComputeRefinedAbstractness(pkggraph.DefaultAbstractnessOpts)
+		var structuralOpts pkggraph.StructuralOpts
+		if cmd.progress {
+			structuralOpts.Progress = pkggraph.ProgressTo(os.Stderr)
+		}
+		graph.ComputeStructuralCouplingWithOpts(structuralOpts)
+		if cohesion {
+			graph.ComputeCohesion()
+		}
+	}
+
+	group, err := pkggraph.ParseLevel(cmd.level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitUsageError
+	}
+	if group != nil {
+		graph = graph.Collapse(group)
+		graph.ComputeGroupMetrics(allPkgs, group, metricsOpts)
+	}
+	if pkggraph.UsesCentrality(cmd.format, cmd.where) {
+		graph.ComputeCentrality()
+	}
+
+	nodes := graph.Sorted
+	if where != nil {
+		nodes, err = predicate.Filter(where, nodes)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	}
+
+	if records != nil {
+		for _, p := range nodes {
+			r := record.FromNode(p)
+			r.Metrics = record.MetricsOf(p)
+			if err := records.Write(r); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+				return subcommands.ExitFailure
+			}
+		}
+		if err := records.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
 	}
 
 	var w io.Writer = os.Stdout
@@ -87,7 +206,7 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		}
 		fmt.Fprintln(w, cmd.header)
 	}
-	for _, p := range graph.Sorted {
+	for _, p := range nodes {
 		err := t.Execute(w, p)
 		fmt.Fprintln(w)
 		if err != nil {
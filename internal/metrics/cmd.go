@@ -11,8 +11,13 @@ import (
 
 	"github.com/google/subcommands"
 
+	"github.com/flamingoosesoftwareinc/goda/internal/coverage"
+	"github.com/flamingoosesoftwareinc/goda/internal/implements"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/predicate"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+	"github.com/flamingoosesoftwareinc/goda/internal/sarif"
 	"github.com/flamingoosesoftwareinc/goda/internal/templates"
 )
 
@@ -20,18 +25,51 @@ var (
 	defaultHeader = "ID\tCa\tCe\tA\tI\tD"
 	defaultFormat = "{{.ID}}\t{{.Ca}}\t{{.Ce}}\t{{printf \"%.2f\" .A}}\t{{printf \"%.2f\" .I}}\t{{printf \"%.2f\" .D}}"
 
-	typesHeader = "ID\tCa\tCe\tA\tI\tD\tSCa\tSCe"
-	typesFormat = "{{.ID}}\t{{.Ca}}\t{{.Ce}}\t{{printf \"%.2f\" .A}}\t{{printf \"%.2f\" .I}}\t{{printf \"%.2f\" .D}}\t{{.SCa}}\t{{.SCe}}"
+	typesHeader = "ID\tCa\tCe\tA\tRA\tI\tD\tRD\tSCa\tSCe\tH\tLCOM\tComp"
+	typesFormat = "{{.ID}}\t{{.Ca}}\t{{.Ce}}\t{{printf \"%.2f\" .A}}\t{{printf \"%.2f\" .RefinedA}}\t{{printf \"%.2f\" .I}}\t{{printf \"%.2f\" .D}}\t{{printf \"%.2f\" .RefinedD}}\t{{.SCa}}\t{{.SCe}}\t{{printf \"%.2f\" .H}}\t{{printf \"%.2f\" .LCOM}}\t{{.Components}}"
 )
 
 type Command struct {
 	printStandard bool
+	hiddenEdges   bool
 	typesMode     bool
+	progress      bool
+	level         string
 
+	scope         string
+	excludeStd    bool
+	excludeVendor bool
+	abstractness  string
+	detail        bool
+
+	coverProfile string
+	where        string
+
+	output  string
 	noAlign bool
 	header  string
 	format  string
 	sortBy  string
+	summary bool
+	sdp     bool
+
+	failIf    string
+	rulesFile string
+	sarif     string
+
+	baseline string
+	compare  string
+
+	plot      string
+	plotSize  string
+	plotColor string
+
+	// centrality is set by Execute when the output refers to the
+	// centrality metrics, which are expensive to compute.
+	centrality bool
+	// cohesion is set by Execute when the output refers to the
+	// cohesion metrics, which require loading the syntax.
+	cohesion bool
 }
 
 func (*Command) Name() string     { return "metrics" }
@@ -52,6 +90,118 @@ func (*Command) Usage() string {
 	       package's interfaces without importing it.
 	  SCe  Structural efferent coupling: packages whose interfaces are
 	       satisfied by this package's types without importing them.
+	       Generic types count when some instantiation satisfies the
+	       interface, constraint interfaces with type sets are ignored.
+	  RA   Refined abstractness: abstract types / all types, computed from
+	       the type checked package scope.
+	  RD   Distance from the main sequence using RA: |RA + I - 1|.
+	  H    Relational cohesion: (R + 1) / N, where N is the number of
+	       package level types and funcs and R the number of references
+	       between them. Methods are part of their receiver type.
+	  LCOM Lack of cohesion: ratio of type and func pairs that don't
+	       refer to each other (0..1).
+	  Comp Connected components of types and funcs, packages with more
+	       than one component could be split.
+
+	Centrality, available in templates and as -sort keys:
+	  PageRank      PageRank of the import graph, rank flows from
+	                importers to the imported packages.
+	  Betweenness   Number of shortest import paths between other
+	                packages passing through the package (Brandes).
+	  Depth         Longest import path to a package without imports.
+	  ReverseDepth  Longest import path from a package without importers.
+
+	System metrics, printed with -summary:
+	  CCD  Cumulative Component Dependency: the sum over all packages of
+	       the number of packages each depends on, including itself.
+	       The per-package count is available in templates as .DependsOn.
+	  ACD  Average Component Dependency: CCD / packages.
+	  NCCD Normalized CCD: CCD divided by the CCD of a balanced binary
+	       tree of the same size, values above 1 indicate entanglement.
+	  Propagation cost: the average fraction of packages affected by a
+	       change in a package, CCD / packages².
+	  Use -o json for a machine-readable summary.
+
+	Stable Dependencies Principle, printed with -sdp:
+	  Dependencies should point in the direction of stability. -sdp
+	  lists the imports where I(importer) < I(imported), i.e. a stable
+	  package depending on a less stable one, largest gap first. With
+	  -where only imports from the matching packages are listed.
+	  Use -o json for a machine-readable list, "graph -sdp" draws the
+	  imports as red edges.
+
+	With -detail the types satisfying interfaces of packages without
+	an import in either direction are listed after the table,
+	see "help implements". -detail requires -types and text output,
+	use "goda implements -o json" for a machine-readable list.
+
+	The -abstractness flag selects which types RA considers, as a comma
+	separated list:
+	  exported     only exported types (default)
+	  all          exported and unexported types
+	  func         count func types as abstract
+	  constraints  count constraint interfaces (with type sets) as abstract,
+	               otherwise they are ignored
+	  aliases      count type aliases by their target, otherwise they are
+	               ignored
+
+	Scope:
+	  By default Ca counts importers among all loaded packages and Ce
+	  counts all imports, including std. -scope expr restricts both to
+	  the packages matching expr, e.g. -scope analyzed for the printed
+	  packages or -scope ./...:mod for the main module. -exclude-std and
+	  -exclude-vendor remove std and vendored imports from Ce.
+
+	Levels:
+	  -level module computes the metrics between modules and -level dir=N
+	  between directories N levels deep inside each module. Only imports
+	  crossing group boundaries are counted. The grouped packages are
+	  available in templates as .Members.
+
+	Thresholds:
+	  -fail-if 'D > 0.7 && Ca > 5' exits with a non-zero status when
+	  any package matches the condition. -rules reads conditions from
+	  a file, which can also allowlist packages with a justification:
+
+	    # comment
+	    fail-if: D > 0.7 && Ca > 5
+	    allow: example.com/legacy/... -- scheduled for removal
+
+	  Conditions use Go syntax and can refer to any field shown by
+	  "help format", e.g. Stat.Go.Lines.
+
+	  -sarif file writes the violations as a SARIF 2.1.0 log for code
+	  scanning, located at the package clause of the first file of the
+	  package. Allowed violations are marked as suppressed. With -sdp
+	  the imports of less stable packages are included as warnings,
+	  located at the import specs, which don't affect the exit status.
+
+	Coverage:
+	  -coverprofile cover.out reads a "go test -coverprofile" file and
+	  sets .Coverage to the percentage of covered statements of each
+	  package. -sort coverage lists the least covered packages first.
+
+	Filtering:
+	  -where 'Ca > 5 && Coverage < 50' prints only the packages matching
+	  the condition, using the same syntax as -fail-if. It doesn't
+	  affect which packages -fail-if, -rules and -sarif check.
+
+	Plotting:
+	  -plot svg or -plot html writes the abstractness vs instability chart
+	  with the main sequence and the zones of pain and uselessness to
+	  stdout. -plot-size lines sizes the points by lines of code.
+	  -plot-color module colors the points by module and -plot-color
+	  coverage from red (uncovered) to green (covered), which requires
+	  -coverprofile. The html variant shows the metrics of a package
+	  when hovering over its point.
+
+	Comparing:
+	  -baseline file.json compares against a snapshot saved with
+	  "goda metrics -o json". -compare rev compares a git revision with
+	  the working tree and -compare rev-a..rev-b two revisions, using
+	  temporary git worktrees. The report contains per-package deltas,
+	  new and removed packages and regressions sorted by the largest
+	  increase of D. Use "-o json" for a machine-readable report.
 
 	See "help expr" for further information about expressions.
 	See "help format" for further information about formatting.
@@ -61,11 +211,35 @@ func (*Command) Usage() string {
 func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 	f.BoolVar(&cmd.typesMode, "types", false, "enable structural coupling analysis (SCa/SCe)")
+	f.BoolVar(&cmd.progress, "progress", false, "report progress of the structural coupling analysis to stderr")
+	f.BoolVar(&cmd.hiddenEdges, "hidden", false, "add edges for dependencies through excluded packages")
+	f.BoolVar(&cmd.detail, "detail", false, "with -types, list the types satisfying interfaces of unconnected packages")
+	f.StringVar(&cmd.abstractness, "abstractness", "exported", "types counted for RA with -types: exported or all, and optionally func, constraints, aliases")
+	f.StringVar(&cmd.scope, "scope", "", "count couplings only within packages matching the expression, \"analyzed\" for the printed packages")
+	f.BoolVar(&cmd.excludeStd, "exclude-std", false, "exclude std imports from Ce")
+	f.BoolVar(&cmd.excludeVendor, "exclude-vendor", false, "exclude vendored imports from Ce")
+	f.StringVar(&cmd.level, "level", "package", "compute metrics for groups of packages (package, module, dir=N, regexp)")
+	f.StringVar(&cmd.coverProfile, "coverprofile", "", "read test coverage from a \"go test -coverprofile\" file into .Coverage")
+	f.StringVar(&cmd.where, "where", "", "print only packages matching the condition, e.g. 'Ca > 5 && Coverage < 50'")
 
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json, ndjson, csv)")
 	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
 	f.StringVar(&cmd.header, "h", "", "header for the table, use \"-\" to skip")
 	f.StringVar(&cmd.format, "f", "", "output format")
-	f.StringVar(&cmd.sortBy, "sort", "d", "sort by: d (distance), ca, ce, a, i, sca, sce, id")
+	f.StringVar(&cmd.sortBy, "sort", "d", "sort by: d (distance), ca, ce, a, i, ra, rd, sca, sce, h, lcom, comp, coverage, pagerank, betweenness, depth, rdepth, id")
+	f.BoolVar(&cmd.summary, "summary", false, "print the system metrics (CCD, ACD, NCCD, propagation cost) instead of the table")
+	f.BoolVar(&cmd.sdp, "sdp", false, "print imports of less stable packages (Stable Dependencies Principle) instead of the table")
+
+	f.StringVar(&cmd.failIf, "fail-if", "", "exit with failure when a package matches the condition, e.g. 'D > 0.7 && Ca > 5'")
+	f.StringVar(&cmd.rulesFile, "rules", "", "file with metric thresholds and allowed packages")
+	f.StringVar(&cmd.sarif, "sarif", "", "write threshold violations and, with -sdp, SDP violations to a SARIF file")
+
+	f.StringVar(&cmd.plot, "plot", "", "plot abstractness vs instability instead of the table (svg, html)")
+	f.StringVar(&cmd.plotSize, "plot-size", "", "size plot points by: none, lines")
+	f.StringVar(&cmd.plotColor, "plot-color", "", "color plot points by: none, module, coverage")
+
+	f.StringVar(&cmd.baseline, "baseline", "", "compare against metrics saved with \"-o json\"")
+	f.StringVar(&cmd.compare, "compare", "", "compare against a git revision (rev) or between two revisions (rev-a..rev-b)")
 }
 
 func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
@@ -91,34 +265,76 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
-	if !cmd.printStandard {
-		go pkgset.LoadStd()
+	if cmd.detail && !cmd.typesMode {
+		fmt.Fprintln(os.Stderr, "-detail requires -types")
+		return subcommands.ExitUsageError
+	}
+	if cmd.detail && !record.IsText(cmd.output) {
+		fmt.Fprintln(os.Stderr, "-detail requires text output, use \"goda implements -o json\" instead")
+		return subcommands.ExitUsageError
 	}
 
-	result, err := pkgset.CalcWithOpts(ctx, f.Args(), pkgset.CalcOpts{
-		TypesMode: cmd.typesMode,
-	})
+	var records record.Writer
+	if !record.IsText(cmd.output) {
+		records, err = record.NewWriter(cmd.output, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+	}
+
+	rules, err := cmd.loadRules()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
-		return subcommands.ExitFailure
+		return subcommands.ExitUsageError
 	}
-
-	// Build graph from the full result for coupling calculations.
-	allPkgs := result
+	cmd.centrality = cmd.usesCentrality(rules)
+	cmd.cohesion = cmd.typesMode && cmd.usesCohesion(rules)
 
 	if !cmd.printStandard {
-		result = pkgset.Subtract(result, pkgset.Std())
+		go pkgset.LoadStd()
 	}
 
-	graph := pkggraph.From(result)
-	graph.ComputeMetrics(allPkgs)
+	if cmd.baseline != "" || cmd.compare != "" {
+		return cmd.executeCompare(ctx, f.Args(), rules)
+	}
 
-	if cmd.typesMode {
-		graph.ComputeStructuralCoupling()
+	graph, err := cmd.graph(ctx, "", f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
 	}
 
 	sorted := make([]*pkggraph.Node, len(graph.Sorted))
 	copy(sorted, graph.Sorted)
+	if cmd.where != "" {
+		where, err := predicate.Parse(cmd.where)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+		sorted, err = predicate.Filter(where, sorted)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	}
+
+	if cmd.summary {
+		if err := cmd.writeSummary(os.Stdout, graph.ComputeSystemMetrics()); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return cmd.checkRules(ctx, rules, graph)
+	}
+
+	if cmd.sdp {
+		if err := cmd.writeSDP(os.Stdout, sdpViolations(graph, sorted)); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return cmd.checkRules(ctx, rules, graph)
+	}
 
 	switch cmd.sortBy {
 	case "d":
@@ -131,14 +347,63 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		sort.Slice(sorted, func(i, k int) bool { return sorted[i].A > sorted[k].A })
 	case "i":
 		sort.Slice(sorted, func(i, k int) bool { return sorted[i].I > sorted[k].I })
+	case "ra":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].RefinedA > sorted[k].RefinedA })
+	case "rd":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].RefinedD > sorted[k].RefinedD })
 	case "sca":
 		sort.Slice(sorted, func(i, k int) bool { return sorted[i].SCa > sorted[k].SCa })
 	case "sce":
 		sort.Slice(sorted, func(i, k int) bool { return sorted[i].SCe > sorted[k].SCe })
+	case "h":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].H > sorted[k].H })
+	case "lcom":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].LCOM > sorted[k].LCOM })
+	case "comp":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].Components > sorted[k].Components })
+	case "coverage":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].Coverage < sorted[k].Coverage })
+	case "pagerank", "pr":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].PageRank > sorted[k].PageRank })
+	case "betweenness", "bc":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].Betweenness > sorted[k].Betweenness })
+	case "depth":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].Depth > sorted[k].Depth })
+	case "rdepth":
+		sort.Slice(sorted, func(i, k int) bool { return sorted[i].ReverseDepth > sorted[k].ReverseDepth })
 	case "id":
 		// already sorted by ID
 	}
 
+	if cmd.plot != "" {
+		err := WritePlot(os.Stdout, sorted, PlotOpts{
+			Format: cmd.plot,
+			Size:   cmd.plotSize,
+			Color:  cmd.plotColor,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return cmd.checkRules(ctx, rules, graph)
+	}
+
+	if records != nil {
+		for _, p := range sorted {
+			r := record.FromNode(p)
+			r.Metrics = record.MetricsOf(p)
+			if err := records.Write(r); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+				return subcommands.ExitFailure
+			}
+		}
+		if err := records.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		return cmd.checkRules(ctx, rules, graph)
+	}
+
 	var w io.Writer = os.Stdout
 	if !cmd.noAlign {
 		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
@@ -157,5 +422,169 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		w.Flush()
 	}
 
+	if cmd.detail {
+		fmt.Fprintln(os.Stdout)
+		fmt.Fprintln(os.Stdout, "structural couplings:")
+		implements.WriteText(os.Stdout, implements.Pairs(graph.Implementations))
+	}
+
+	return cmd.checkRules(ctx, rules, graph)
+}
+
+// graph loads the packages matching expr in dir and computes their metrics.
+func (cmd *Command) graph(ctx context.Context, dir string, expr []string) (*pkggraph.Graph, error) {
+	result, err := pkgset.CalcWithOpts(ctx, expr, pkgset.CalcOpts{
+		TypesMode:  cmd.typesMode,
+		SyntaxMode: cmd.cohesion,
+		Dir:        dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Build graph from the full result for coupling calculations.
+	allPkgs := result
+
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.FromWithOpts(result, pkggraph.FromOpts{
+		HiddenEdges: cmd.hiddenEdges,
+	})
+
+	metricsOpts, err := cmd.metricsOpts(ctx, dir, result)
+	if err != nil {
+		return nil, err
+	}
+	graph.ComputeMetricsWithOpts(allPkgs, metricsOpts)
+
+	if cmd.coverProfile != "" {
+		profile, err := coverage.Load(cmd.coverProfile)
+		if err != nil {
+			return nil, err
+		}
+		coverage.Apply(graph, profile)
+	}
+
+	if cmd.typesMode {
+		opts, err := pkggraph.ParseAbstractnessOpts(cmd.abstractness)
+		if err != nil {
+			return nil, err
+		}
+		graph.ComputeRefinedAbstractness(opts)
+		var structuralOpts pkggraph.StructuralOpts
+		if cmd.progress {
+			structuralOpts.Progress = pkggraph.ProgressTo(os.Stderr)
+		}
+		graph.ComputeStructuralCouplingWithOpts(structuralOpts)
+		if cmd.cohesion {
+			graph.ComputeCohesion()
+		}
+	}
+
+	group, err := pkggraph.ParseLevel(cmd.level)
+	if err != nil {
+		return nil, err
+	}
+	if group != nil {
+		graph = graph.Collapse(group)
+		graph.ComputeGroupMetrics(allPkgs, group, metricsOpts)
+	}
+	if cmd.centrality {
+		graph.ComputeCentrality()
+	}
+	return graph, nil
+}
+
+// metricsOpts evaluates -scope and the Ce exclusions.
+// analyzed is the set used for "-scope analyzed".
+func (cmd *Command) metricsOpts(ctx context.Context, dir string, analyzed pkgset.Set) (pkggraph.MetricsOpts, error) {
+	scope, err := pkggraph.ParseScope(ctx, cmd.scope, dir, analyzed)
+	if err != nil {
+		return pkggraph.MetricsOpts{}, err
+	}
+	return pkggraph.MetricsOpts{
+		Scope:         scope,
+		ExcludeStd:    cmd.excludeStd,
+		ExcludeVendor: cmd.excludeVendor,
+	}, nil
+}
+
+// usesCentrality returns whether the format, sort key or conditions
+// refer to the centrality metrics.
+func (cmd *Command) usesCentrality(rules *Rules) bool {
+	switch cmd.sortBy {
+	case "pagerank", "pr", "betweenness", "bc", "depth", "rdepth":
+		return true
+	}
+	texts := []string{cmd.format, cmd.where}
+	for _, rule := range rules.FailIf {
+		texts = append(texts, rule.String())
+	}
+	return pkggraph.UsesCentrality(texts...)
+}
+
+// usesCohesion returns whether the output, sort key or conditions
+// refer to the cohesion metrics.
+func (cmd *Command) usesCohesion(rules *Rules) bool {
+	switch cmd.sortBy {
+	case "h", "lcom", "comp":
+		return true
+	}
+	if !record.IsText(cmd.output) {
+		return true
+	}
+	texts := []string{cmd.format, cmd.where}
+	for _, rule := range rules.FailIf {
+		texts = append(texts, rule.String())
+	}
+	return pkggraph.UsesCohesion(texts...)
+}
+
+// loadRules combines -rules and -fail-if.
+func (cmd *Command) loadRules() (*Rules, error) {
+	rules := &Rules{}
+	if cmd.rulesFile != "" {
+		var err error
+		rules, err = LoadRules(cmd.rulesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cmd.failIf != "" {
+		if err := rules.AddFailIf(cmd.failIf); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// checkRules reports violations of all packages in graph, regardless of
+// -where, to stderr and -sarif, and fails when any of them is not allowed.
+func (cmd *Command) checkRules(ctx context.Context, rules *Rules, graph *pkggraph.Graph) subcommands.ExitStatus {
+	if rules.Empty() && cmd.sarif == "" {
+		return subcommands.ExitSuccess
+	}
+
+	var violations []Violation
+	if !rules.Empty() {
+		var err error
+		violations, err = rules.Check(graph.Sorted)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	}
+	if cmd.sarif != "" {
+		log := cmd.sarifLog(sarif.Root(ctx), graph, violations)
+		if err := log.WriteFile(cmd.sarif); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write SARIF: %v\n", err)
+			return subcommands.ExitFailure
+		}
+	}
+	if writeViolations(os.Stderr, violations) {
+		return subcommands.ExitFailure
+	}
 	return subcommands.ExitSuccess
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -49,6 +50,36 @@ func TestMetrics(t *testing.T) {
 			args:   []string{"metrics", "-types", "-std", "-sort", "id", "./..."},
 			golden: "metrics_types.golden",
 		},
+		{
+			name:   "metrics_csv",
+			args:   []string{"metrics", "-std", "-sort", "id", "-o", "csv", "./..."},
+			golden: "metrics_csv.golden",
+		},
+		{
+			name:   "metrics_baseline",
+			args:   []string{"metrics", "-std", "-baseline", "../baseline.json", "./..."},
+			golden: "metrics_baseline.golden",
+		},
+		{
+			name:   "metrics_plot_svg",
+			args:   []string{"metrics", "-std", "-plot", "svg", "-plot-size", "lines", "-plot-color", "module", "./..."},
+			golden: "metrics_plot.golden",
+		},
+		{
+			name:   "metrics_summary",
+			args:   []string{"metrics", "-std", "-summary", "./..."},
+			golden: "metrics_summary.golden",
+		},
+		{
+			name: "metrics_centrality",
+			args: []string{
+				"metrics", "-std", "-sort", "pagerank",
+				"-h", "ID\tPageRank\tBetweenness\tDepth\tReverseDepth",
+				"-f", "{{.ID}}\t{{printf \"%.3f\" .PageRank}}\t{{printf \"%.2f\" .Betweenness}}\t{{.Depth}}\t{{.ReverseDepth}}",
+				"./...",
+			},
+			golden: "metrics_centrality.golden",
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,3 +126,92 @@ func buildGoda(t *testing.T) string {
 
 	return bin
 }
+
+func TestMetricsFailIf(t *testing.T) {
+	goda := buildGoda(t)
+
+	projectDir, err := filepath.Abs(filepath.Join("testdata", "testproject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rulesFile := filepath.Join(t.TempDir(), "goda.rules")
+	err = os.WriteFile(rulesFile, []byte(
+		"# thresholds\n"+
+			"fail-if: D > 0.9\n"+
+			"allow: testproject/compat -- kept for old clients\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		fail bool
+	}{
+		{
+			name: "violation",
+			args: []string{"metrics", "-std", "-fail-if", "D > 0.4 && Ca > 0", "./..."},
+			fail: true,
+		},
+		{
+			name: "violation_outside_where",
+			args: []string{"metrics", "-std", "-where", "Ca > 2", "-fail-if", "D > 0.4 && Ca > 0", "./..."},
+			fail: true,
+		},
+		{
+			name: "no_violation",
+			args: []string{"metrics", "-std", "-fail-if", "D > 1", "./..."},
+		},
+		{
+			name: "allowed",
+			args: []string{"metrics", "-std", "-rules", rulesFile, "./..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(goda, tt.args...)
+			cmd.Dir = projectDir
+			got, err := cmd.CombinedOutput()
+			if failed := err != nil; failed != tt.fail {
+				t.Fatalf("goda %v: got failure %v, want %v\n%s", tt.args, failed, tt.fail, got)
+			}
+			if tt.fail && !strings.Contains(string(got), "testproject/types: D > 0.4 && Ca > 0 (D=0.50 Ca=1)") {
+				t.Errorf("missing violation in output:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestMetricsSARIF(t *testing.T) {
+	goda := buildGoda(t)
+
+	projectDir, err := filepath.Abs(filepath.Join("testdata", "testproject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sarifFile := filepath.Join(t.TempDir(), "goda.sarif")
+
+	cmd := exec.Command(goda, "metrics", "-std", "-fail-if", "D > 0.4 && Ca > 0", "-sarif", sarifFile, "./...")
+	cmd.Dir = projectDir
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected failure\n%s", out)
+	}
+
+	got, err := os.ReadFile(sarifFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"version": "2.1.0"`,
+		`"ruleId": "fail-if"`,
+		`"text": "testproject/types: D > 0.4 && Ca > 0 (D=0.50 Ca=1)"`,
+		`testproject/types/types.go"`,
+		`"startLine": 1`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("missing %s in SARIF log:\n%s", want, got)
+		}
+	}
+}
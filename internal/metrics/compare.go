@@ -0,0 +1,313 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+// Snapshot contains the metrics of packages at some point in time.
+type Snapshot struct {
+	// Name describes the source of the snapshot, e.g. a file or a revision.
+	Name    string
+	Metrics map[string]record.Metrics
+}
+
+// SnapshotOf creates a snapshot from the computed metrics of nodes.
+func SnapshotOf(name string, nodes []*pkggraph.Node) *Snapshot {
+	snapshot := &Snapshot{Name: name, Metrics: map[string]record.Metrics{}}
+	for _, n := range nodes {
+		snapshot.Metrics[n.ID] = *record.MetricsOf(n)
+	}
+	return snapshot
+}
+
+// LoadSnapshot reads a snapshot saved with "goda metrics -o json" or "-o ndjson".
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []record.Package
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &packages); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var p record.Package
+			if err := dec.Decode(&p); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			packages = append(packages, p)
+		}
+	}
+
+	snapshot := &Snapshot{Name: path, Metrics: map[string]record.Metrics{}}
+	for _, p := range packages {
+		if p.Version != record.Version {
+			return nil, fmt.Errorf("%s: unsupported record version %d, expected %d", path, p.Version, record.Version)
+		}
+		if p.Metrics == nil {
+			return nil, fmt.Errorf("%s: missing metrics for %s", path, p.ID)
+		}
+		snapshot.Metrics[p.ID] = *p.Metrics
+	}
+	return snapshot, nil
+}
+
+// Comparison is the difference between two snapshots.
+type Comparison struct {
+	Old string
+	New string
+
+	// Changed are the packages present in both snapshots with different metrics,
+	// largest increase of D first.
+	Changed []PackageDelta `json:",omitempty"`
+	// Added are the packages only present in the new snapshot.
+	Added []PackageMetrics `json:",omitempty"`
+	// Removed are the packages only present in the old snapshot.
+	Removed []PackageMetrics `json:",omitempty"`
+	// Regressions are the changed packages where D increased, largest increase first.
+	Regressions []PackageDelta `json:",omitempty"`
+}
+
+// PackageMetrics are the metrics of a single package.
+type PackageMetrics struct {
+	ID      string
+	Metrics record.Metrics
+}
+
+// PackageDelta is the change of metrics of a single package.
+type PackageDelta struct {
+	ID    string
+	Old   record.Metrics
+	New   record.Metrics
+	Delta record.Metrics
+}
+
+// Compare computes the differences from old to new.
+func Compare(old, new *Snapshot) *Comparison {
+	c := &Comparison{Old: old.Name, New: new.Name}
+
+	for _, id := range sortedIDs(new.Metrics) {
+		next := new.Metrics[id]
+		prev, ok := old.Metrics[id]
+		if !ok {
+			c.Added = append(c.Added, PackageMetrics{ID: id, Metrics: next})
+			continue
+		}
+
+		delta := subtractMetrics(next, prev)
+		if delta == (record.Metrics{}) {
+			continue
+		}
+		change := PackageDelta{ID: id, Old: prev, New: next, Delta: delta}
+		c.Changed = append(c.Changed, change)
+		if delta.D > 0 {
+			c.Regressions = append(c.Regressions, change)
+		}
+	}
+
+	for _, id := range sortedIDs(old.Metrics) {
+		if _, ok := new.Metrics[id]; !ok {
+			c.Removed = append(c.Removed, PackageMetrics{ID: id, Metrics: old.Metrics[id]})
+		}
+	}
+
+	sort.SliceStable(c.Changed, func(i, k int) bool {
+		return c.Changed[i].Delta.D > c.Changed[k].Delta.D
+	})
+	sort.SliceStable(c.Regressions, func(i, k int) bool {
+		return c.Regressions[i].Delta.D > c.Regressions[k].Delta.D
+	})
+
+	return c
+}
+
+func sortedIDs(metrics map[string]record.Metrics) []string {
+	ids := make([]string, 0, len(metrics))
+	for id := range metrics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// subtractMetrics returns a - b, ignoring floating point noise.
+func subtractMetrics(a, b record.Metrics) record.Metrics {
+	sub := func(x, y float64) float64 {
+		d := x - y
+		if math.Abs(d) < 1e-9 {
+			return 0
+		}
+		return d
+	}
+	return record.Metrics{
+		Ca: sub(a.Ca, b.Ca), Ce: sub(a.Ce, b.Ce),
+		A: sub(a.A, b.A), I: sub(a.I, b.I), D: sub(a.D, b.D),
+		SCa: sub(a.SCa, b.SCa), SCe: sub(a.SCe, b.SCe),
+		RefinedA: sub(a.RefinedA, b.RefinedA), RefinedD: sub(a.RefinedD, b.RefinedD),
+		H: sub(a.H, b.H), LCOM: sub(a.LCOM, b.LCOM), Components: a.Components - b.Components,
+	}
+}
+
+// executeCompare prints the comparison against -baseline or -compare.
+func (cmd *Command) executeCompare(ctx context.Context, expr []string, rules *Rules) subcommands.ExitStatus {
+	if cmd.baseline != "" && cmd.compare != "" {
+		fmt.Fprintln(os.Stderr, "-baseline and -compare cannot be used together")
+		return subcommands.ExitUsageError
+	}
+	output := strings.ToLower(cmd.output)
+	if !record.IsText(output) && output != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q for comparison, expected text or json\n", cmd.output)
+		return subcommands.ExitUsageError
+	}
+	if strings.Contains(cmd.compare, "...") {
+		fmt.Fprintf(os.Stderr, "invalid -compare %q, expected rev or rev-a..rev-b\n", cmd.compare)
+		return subcommands.ExitUsageError
+	}
+
+	var old *Snapshot
+	var graph *pkggraph.Graph
+	var err error
+	if cmd.baseline != "" {
+		old, err = LoadSnapshot(cmd.baseline)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	} else {
+		revA, revB, twoRevisions := strings.Cut(cmd.compare, "..")
+		oldGraph, err := cmd.graphAt(ctx, revA, expr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		old = SnapshotOf(revA, oldGraph.Sorted)
+
+		if twoRevisions {
+			graph, err = cmd.graphAt(ctx, revB, expr)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				return subcommands.ExitFailure
+			}
+		}
+	}
+
+	name := "working tree"
+	if graph == nil {
+		graph, err = cmd.graph(ctx, "", expr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	} else {
+		_, name, _ = strings.Cut(cmd.compare, "..")
+	}
+
+	comparison := Compare(old, SnapshotOf(name, graph.Sorted))
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(comparison); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+	} else {
+		cmd.writeComparison(os.Stdout, comparison)
+	}
+
+	return cmd.checkRules(ctx, rules, graph)
+}
+
+// graphAt computes the metrics at revision rev.
+func (cmd *Command) graphAt(ctx context.Context, rev string, expr []string) (*pkggraph.Graph, error) {
+	worktree, err := git.AddWorktree(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = worktree.Remove(ctx) }()
+
+	graph, err := cmd.graph(ctx, worktree.Dir, expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rev, err)
+	}
+	return graph, nil
+}
+
+func (cmd *Command) writeComparison(out io.Writer, c *Comparison) {
+	var w io.Writer = out
+	if !cmd.noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintf(w, "comparing %s with %s\n", c.Old, c.New)
+
+	if len(c.Changed) > 0 {
+		fmt.Fprintln(w)
+		if cmd.typesMode {
+			fmt.Fprintln(w, "ID\tD\tΔCa\tΔCe\tΔA\tΔI\tΔD\tΔSCa\tΔSCe")
+		} else {
+			fmt.Fprintln(w, "ID\tD\tΔCa\tΔCe\tΔA\tΔI\tΔD")
+		}
+		for _, p := range c.Changed {
+			fmt.Fprintf(w, "%s\t%.2f\t%+g\t%+g\t%+.2f\t%+.2f\t%+.2f",
+				p.ID, p.New.D, p.Delta.Ca, p.Delta.Ce, p.Delta.A, p.Delta.I, p.Delta.D)
+			if cmd.typesMode {
+				fmt.Fprintf(w, "\t%+g\t%+g", p.Delta.SCa, p.Delta.SCe)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if len(c.Added) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "new packages:")
+		for _, p := range c.Added {
+			fmt.Fprintf(w, "    %s\tD=%.2f\n", p.ID, p.Metrics.D)
+		}
+	}
+
+	if len(c.Removed) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "removed packages:")
+		for _, p := range c.Removed {
+			fmt.Fprintf(w, "    %s\n", p.ID)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(c.Regressions) == 0 {
+		fmt.Fprintln(w, "no regressions")
+	} else {
+		fmt.Fprintln(w, "regressions:")
+		for _, p := range c.Regressions {
+			fmt.Fprintf(w, "    %s\tD %.2f -> %.2f\t(%+.2f)\n", p.ID, p.Old.D, p.New.D, p.Delta.D)
+		}
+	}
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		w.Flush()
+	}
+}
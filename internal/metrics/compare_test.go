@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+func TestCompare(t *testing.T) {
+	old := &Snapshot{Name: "old", Metrics: map[string]record.Metrics{
+		"a": {D: 0.5},
+		"b": {D: 0.1},
+		"c": {D: 0.2},
+		"d": {D: 0.3},
+	}}
+	new := &Snapshot{Name: "new", Metrics: map[string]record.Metrics{
+		"a": {D: 0.2},
+		"b": {D: 0.9},
+		"c": {D: 0.4},
+		"d": {D: 0.3},
+	}}
+
+	c := Compare(old, new)
+	var changed, regressions []string
+	for _, p := range c.Changed {
+		changed = append(changed, p.ID)
+	}
+	for _, p := range c.Regressions {
+		regressions = append(regressions, p.ID)
+	}
+	if got, want := changed, []string{"b", "c", "a"}; !slices.Equal(got, want) {
+		t.Errorf("got changed %v, want %v", got, want)
+	}
+	if got, want := regressions, []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("got regressions %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+)
+
+// PlotOpts configures the main sequence plot.
+type PlotOpts struct {
+	// Format is either svg or html.
+	Format string
+	// Size is either empty for equal points or "lines" to size points by lines of code.
+	Size string
+	// Color is either empty for a single color, "module" to color points by module
+	// or "coverage" to color points by test coverage.
+	Color string
+}
+
+// Validate checks whether the options are supported.
+func (opts PlotOpts) Validate() error {
+	switch opts.Format {
+	case "svg", "html":
+	default:
+		return fmt.Errorf("unknown plot format %q, expected svg or html", opts.Format)
+	}
+	switch opts.Size {
+	case "", "none", "lines":
+	default:
+		return fmt.Errorf("unknown plot size %q, expected none or lines", opts.Size)
+	}
+	switch opts.Color {
+	case "", "none", "module", "coverage":
+	default:
+		return fmt.Errorf("unknown plot color %q, expected none, module or coverage", opts.Color)
+	}
+	return nil
+}
+
+const (
+	plotSize   = 600.0
+	plotMargin = 60.0
+	plotLegend = 260.0
+
+	// zoneRadius is the radius of the zones of pain and uselessness.
+	zoneRadius = 0.35
+
+	defaultPointColor = "#4477aa"
+)
+
+// plotPalette contains colors for distinguishing modules.
+var plotPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+type plotPoint struct {
+	node   *pkggraph.Node
+	x, y   float64
+	radius float64
+	color  string
+	tip    string
+}
+
+// WritePlot writes the abstractness vs instability chart of nodes,
+// with the main sequence and the zones of pain and uselessness.
+func WritePlot(w io.Writer, nodes []*pkggraph.Node, opts PlotOpts) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	var svg strings.Builder
+	writePlotSVG(&svg, nodes, opts)
+
+	if opts.Format == "svg" {
+		_, err := io.WriteString(w, svg.String())
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, plotHTML, svg.String())
+	return err
+}
+
+func writePlotSVG(w io.Writer, nodes []*pkggraph.Node, opts PlotOpts) {
+	px := func(i float64) float64 { return plotMargin + i*plotSize }
+	py := func(a float64) float64 { return plotMargin + (1-a)*plotSize }
+
+	modules := plotModules(nodes)
+	legend := opts.Color == "module" && len(modules) > 0
+
+	width := plotSize + 2*plotMargin
+	if legend {
+		width += plotLegend
+	}
+	height := plotSize + 2*plotMargin
+
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\" font-family=\"sans-serif\" font-size=\"12\">\n", width, height, width, height)
+	fmt.Fprintf(w, "<rect width=\"%.0f\" height=\"%.0f\" fill=\"white\"/>\n", width, height)
+
+	// Zones of pain and uselessness.
+	r := zoneRadius * plotSize
+	fmt.Fprintf(w, "<path d=\"M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 0 0 %.1f,%.1f Z\" fill=\"#e15759\" fill-opacity=\"0.15\"/>\n",
+		px(0), py(0), px(zoneRadius), py(0), r, r, px(0), py(zoneRadius))
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" fill=\"#b03030\">zone of pain</text>\n", px(0.02), py(0.03))
+	fmt.Fprintf(w, "<path d=\"M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 0 0 %.1f,%.1f Z\" fill=\"#edc948\" fill-opacity=\"0.2\"/>\n",
+		px(1), py(1), px(1-zoneRadius), py(1), r, r, px(1), py(1-zoneRadius))
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" fill=\"#8a6d00\" text-anchor=\"end\">zone of uselessness</text>\n", px(0.98), py(0.96))
+
+	// Main sequence.
+	fmt.Fprintf(w, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#888\" stroke-dasharray=\"6,4\"/>\n", px(0), py(1), px(1), py(0))
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" fill=\"#666\" transform=\"rotate(45 %.1f %.1f)\" text-anchor=\"middle\">main sequence</text>\n", px(0.5)+8, py(0.5)-8, px(0.5)+8, py(0.5)-8)
+
+	// Axes.
+	fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"none\" stroke=\"#333\"/>\n", px(0), py(1), plotSize, plotSize)
+	for _, tick := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%.2f</text>\n", px(tick), py(0)+18, tick)
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%.2f</text>\n", px(0)-6, py(tick)+4, tick)
+	}
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">Instability (I)</text>\n", px(0.5), py(0)+40)
+	fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\" transform=\"rotate(-90 %.1f %.1f)\">Abstractness (A)</text>\n", px(0)-42, py(0.5), px(0)-42, py(0.5))
+
+	// Points, larger ones first so that smaller ones remain visible.
+	points := plotPoints(nodes, opts, modules)
+	sort.SliceStable(points, func(i, k int) bool { return points[i].radius > points[k].radius })
+	for _, p := range points {
+		tip := html.EscapeString(p.tip)
+		// XML parsers normalize newlines in attributes to spaces, unless escaped.
+		attr := strings.ReplaceAll(tip, "\n", "&#10;")
+		fmt.Fprintf(w, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\" fill=\"%s\" fill-opacity=\"0.7\" stroke=\"#333\" stroke-width=\"0.5\" data-tip=\"%s\"><title>%s</title></circle>\n",
+			px(p.x), py(p.y), p.radius, p.color, attr, tip)
+	}
+
+	if legend {
+		x := px(1) + 30
+		for i, module := range modules {
+			y := py(1) + float64(i)*18
+			fmt.Fprintf(w, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"5\" fill=\"%s\"/>\n", x, y, moduleColor(modules, module))
+			fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%.1f\">%s</text>\n", x+12, y+4, html.EscapeString(module))
+		}
+	}
+
+	fmt.Fprintln(w, "</svg>")
+}
+
+func plotPoints(nodes []*pkggraph.Node, opts PlotOpts, modules []string) []plotPoint {
+	maxLines := 0
+	for _, n := range nodes {
+		maxLines = max(maxLines, n.Stat.Go.Lines)
+	}
+
+	var points []plotPoint
+	for _, n := range nodes {
+		p := plotPoint{
+			node:   n,
+			x:      n.I,
+			y:      n.A,
+			radius: 5,
+			color:  defaultPointColor,
+		}
+		if opts.Size == "lines" && maxLines > 0 {
+			p.radius = 3 + 17*math.Sqrt(float64(n.Stat.Go.Lines)/float64(maxLines))
+		}
+		switch opts.Color {
+		case "module":
+			p.color = moduleColor(modules, moduleOf(n))
+		case "coverage":
+			p.color = n.CoverageColor()
+		}
+		p.tip = fmt.Sprintf("%s\nCa=%v Ce=%v\nA=%.2f I=%.2f D=%.2f\nlines=%d",
+			n.ID, n.Ca, n.Ce, n.A, n.I, n.D, n.Stat.Go.Lines)
+		if n.Statements > 0 {
+			p.tip += fmt.Sprintf(" coverage=%.1f%%", n.Coverage)
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// plotModules returns the sorted distinct modules of nodes.
+func plotModules(nodes []*pkggraph.Node) []string {
+	seen := map[string]bool{}
+	var modules []string
+	for _, n := range nodes {
+		module := moduleOf(n)
+		if !seen[module] {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+func moduleOf(n *pkggraph.Node) string {
+	return pkggraph.ByModule(n)
+}
+
+// moduleColor picks a palette color for module, falling back to
+// a hash based color when there are more modules than colors.
+func moduleColor(modules []string, module string) string {
+	if len(modules) <= len(plotPalette) {
+		return plotPalette[sort.SearchStrings(modules, module)]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(module))
+	return plotPalette[h.Sum32()%uint32(len(plotPalette))]
+}
+
+const plotHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goda metrics</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+circle[data-tip]:hover { stroke-width: 2; }
+#tooltip {
+	position: fixed; display: none; pointer-events: none;
+	padding: 6px 8px; background: #222; color: #fff;
+	font-size: 12px; white-space: pre; border-radius: 4px;
+}
+</style>
+</head>
+<body>
+%s<div id="tooltip"></div>
+<script>
+(function() {
+	var tooltip = document.getElementById("tooltip");
+	document.querySelectorAll("circle[data-tip]").forEach(function(point) {
+		var title = point.querySelector("title");
+		if (title) point.removeChild(title);
+		point.addEventListener("mousemove", function(ev) {
+			tooltip.textContent = point.getAttribute("data-tip");
+			tooltip.style.left = (ev.clientX + 12) + "px";
+			tooltip.style.top = (ev.clientY + 12) + "px";
+			tooltip.style.display = "block";
+		});
+		point.addEventListener("mouseleave", function() {
+			tooltip.style.display = "none";
+		});
+	});
+})();
+</script>
+</body>
+</html>
+`
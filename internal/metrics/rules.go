@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/predicate"
+)
+
+// Rules are metric thresholds that packages must not exceed.
+//
+// The rules file contains one rule per line:
+//
+//	# comment
+//	fail-if: D > 0.7 && Ca > 5
+//	allow: example.com/legacy/... -- scheduled for removal
+//
+// "fail-if" specifies a condition that fails the check and
+// "allow" exempts packages matching the pattern, with an optional
+// justification after "--".
+type Rules struct {
+	FailIf []*predicate.Expr
+	Allow  []Allow
+}
+
+// Allow exempts packages from the rules.
+type Allow struct {
+	// Pattern is a package path, which may end with "/..." to match subpackages.
+	Pattern       string
+	Justification string
+
+	rx *regexp.Regexp
+}
+
+// Violation is a package that matches a fail-if rule.
+type Violation struct {
+	Node *pkggraph.Node
+	Rule *predicate.Expr
+	// Values are the values of the metrics used in the rule.
+	Values []Value
+	// Allowed is the matching allow rule, when the package is exempt.
+	Allowed *Allow
+}
+
+// Value is the value of a metric for a package.
+type Value struct {
+	Name  string
+	Value any
+}
+
+// String formats the value as "name=value".
+func (v Value) String() string {
+	if f, ok := v.Value.(float64); ok {
+		if f == math.Trunc(f) {
+			return v.Name + "=" + strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return v.Name + "=" + strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	return fmt.Sprintf("%s=%v", v.Name, v.Value)
+}
+
+// LoadRules reads rules from a file.
+func LoadRules(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	rules, err := ParseRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules parses the rules file format.
+func ParseRules(r io.Reader) (*Rules, error) {
+	rules := &Rules{}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "fail-if":
+			if err := rules.AddFailIf(value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		case "allow":
+			pattern, justification, _ := strings.Cut(value, "--")
+			if err := rules.AddAllow(strings.TrimSpace(pattern), strings.TrimSpace(justification)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unknown rule %q", lineNumber, key)
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+// AddFailIf adds a failure condition.
+func (rules *Rules) AddFailIf(condition string) error {
+	expr, err := predicate.Parse(condition)
+	if err != nil {
+		return err
+	}
+	rules.FailIf = append(rules.FailIf, expr)
+	return nil
+}
+
+// AddAllow adds an exemption for packages matching pattern.
+func (rules *Rules) AddAllow(pattern, justification string) error {
+	if pattern == "" {
+		return fmt.Errorf("missing package pattern")
+	}
+	rules.Allow = append(rules.Allow, Allow{
+		Pattern:       pattern,
+		Justification: justification,
+		rx:            patternRegexp(pattern),
+	})
+	return nil
+}
+
+// Empty returns whether there are no conditions to check.
+func (rules *Rules) Empty() bool {
+	return rules == nil || len(rules.FailIf) == 0
+}
+
+// Check evaluates the rules against nodes and returns the violations,
+// including the allowed ones.
+func (rules *Rules) Check(nodes []*pkggraph.Node) ([]Violation, error) {
+	var violations []Violation
+	for _, n := range nodes {
+		for _, rule := range rules.FailIf {
+			failed, err := rule.Eval(n)
+			if err != nil {
+				return nil, err
+			}
+			if !failed {
+				continue
+			}
+
+			violation := Violation{
+				Node:    n,
+				Rule:    rule,
+				Allowed: rules.allowed(n.ID),
+			}
+			for _, name := range rule.Names() {
+				value, err := predicate.Lookup(n, name)
+				if err != nil {
+					return nil, err
+				}
+				violation.Values = append(violation.Values, Value{Name: name, Value: value})
+			}
+			violations = append(violations, violation)
+		}
+	}
+	return violations, nil
+}
+
+func (rules *Rules) allowed(id string) *Allow {
+	for i := range rules.Allow {
+		if rules.Allow[i].rx.MatchString(id) {
+			return &rules.Allow[i]
+		}
+	}
+	return nil
+}
+
+// patternRegexp converts a package pattern to a regexp,
+// where "..." matches any string, same as for the go command.
+func patternRegexp(pattern string) *regexp.Regexp {
+	rx := regexp.QuoteMeta(pattern)
+	rx = strings.ReplaceAll(rx, `\.\.\.`, `.*`)
+	if strings.HasSuffix(rx, `/.*`) {
+		rx = strings.TrimSuffix(rx, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile(`^` + rx + `$`)
+}
+
+// writeViolations prints violations and returns whether any of them is not allowed.
+func writeViolations(w io.Writer, violations []Violation) bool {
+	failed := false
+	for _, v := range violations {
+		if v.Allowed != nil {
+			continue
+		}
+		if !failed {
+			fmt.Fprintln(w, "metric violations:")
+			failed = true
+		}
+		fmt.Fprintf(w, "    %s: %s (%s)\n", v.Node.ID, v.Rule, joinValues(v.Values))
+	}
+
+	allowedHeader := false
+	for _, v := range violations {
+		if v.Allowed == nil {
+			continue
+		}
+		if !allowedHeader {
+			fmt.Fprintln(w, "allowed violations:")
+			allowedHeader = true
+		}
+		fmt.Fprintf(w, "    %s: %s (%s)", v.Node.ID, v.Rule, joinValues(v.Values))
+		if v.Allowed.Justification != "" {
+			fmt.Fprintf(w, " -- %s", v.Allowed.Justification)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return failed
+}
+
+func joinValues(values []Value) string {
+	var xs []string
+	for _, v := range values {
+		xs = append(xs, v.String())
+	}
+	return strings.Join(xs, " ")
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader(`
+# comment
+fail-if: D > 0.7 && Ca > 5
+allow: example.com/legacy/... -- scheduled for removal
+allow: example.com/gen
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules.FailIf) != 1 || rules.FailIf[0].String() != "D > 0.7 && Ca > 5" {
+		t.Errorf("got fail-if %v", rules.FailIf)
+	}
+	if len(rules.Allow) != 2 || rules.Allow[0].Justification != "scheduled for removal" {
+		t.Errorf("got allow %+v", rules.Allow)
+	}
+
+	for id, want := range map[string]bool{
+		"example.com/legacy":     true,
+		"example.com/legacy/x/y": true,
+		"example.com/legacyx":    false,
+		"example.com/gen":        true,
+		"example.com/gen/x":      false,
+	} {
+		if got := rules.allowed(id) != nil; got != want {
+			t.Errorf("allowed(%q) = %v, want %v", id, got, want)
+		}
+	}
+
+	for _, invalid := range []string{"fail-if: D >", "deny: x", "D > 1", "allow:"} {
+		if _, err := ParseRules(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
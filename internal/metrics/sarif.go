@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/sarif"
+)
+
+// sarifLog returns the threshold violations and, with -sdp, the SDP
+// violations of graph as SARIF results.
+func (cmd *Command) sarifLog(root string, graph *pkggraph.Graph, violations []Violation) *sarif.Log {
+	log := &sarif.Log{Root: root}
+	log.AddRule(sarif.Rule{ID: "fail-if", Description: "Package metrics matching a fail-if condition."})
+	if cmd.sdp {
+		log.AddRule(sarif.Rule{ID: "sdp", Description: "Import of a less stable package, breaking the Stable Dependencies Principle."})
+	}
+
+	for _, v := range violations {
+		result := sarif.Result{
+			RuleID:  "fail-if",
+			Level:   sarif.Error,
+			Message: fmt.Sprintf("%s: %s (%s)", v.Node.ID, v.Rule, joinValues(v.Values)),
+		}
+		result.File, result.Line = packageClause(v.Node)
+		if v.Allowed != nil {
+			result.Suppressed = true
+			result.Justification = v.Allowed.Justification
+		}
+		log.Add(result)
+	}
+
+	if !cmd.sdp {
+		return log
+	}
+	for _, v := range sdpViolations(graph, graph.Sorted) {
+		result := sarif.Result{
+			RuleID:  "sdp",
+			Level:   sarif.Warning,
+			Message: fmt.Sprintf("%s (I=%.2f) imports the less stable %s (I=%.2f)", v.From, v.FromI, v.To, v.ToI),
+		}
+		if len(v.Imports) == 0 {
+			log.Add(result)
+			continue
+		}
+		for _, imp := range v.Imports {
+			result.File, result.Line = imp.File, imp.Line
+			log.Add(result)
+		}
+	}
+	return log
+}
+
+// packageClause returns the position of the package clause in the
+// first Go file of n, or no position when it cannot be parsed.
+func packageClause(n *pkggraph.Node) (string, int) {
+	if n.Package == nil || len(n.GoFiles) == 0 {
+		return "", 0
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, n.GoFiles[0], nil, parser.PackageClauseOnly)
+	if err != nil {
+		return n.GoFiles[0], 0
+	}
+	return n.GoFiles[0], fset.Position(f.Package).Line
+}
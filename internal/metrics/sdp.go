@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+// sdpViolations returns the SDP violations of graph importing from nodes.
+func sdpViolations(graph *pkggraph.Graph, nodes []*pkggraph.Node) []pkggraph.SDPViolation {
+	from := map[string]bool{}
+	for _, n := range nodes {
+		from[n.ID] = true
+	}
+	violations := []pkggraph.SDPViolation{}
+	for _, v := range graph.SDPViolations() {
+		if from[v.From] {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// writeSDP prints the SDP violations as text or json.
+func (cmd *Command) writeSDP(out io.Writer, violations []pkggraph.SDPViolation) error {
+	output := strings.ToLower(cmd.output)
+	switch {
+	case output == "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(violations)
+	case record.IsText(output):
+	default:
+		return fmt.Errorf("unsupported output format %q for -sdp, expected text or json", cmd.output)
+	}
+
+	var w io.Writer = out
+	if !cmd.noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintln(w, "From\tTo\tI(From)\tI(To)\tGap")
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%.2f\n", v.From, v.To, v.FromI, v.ToI, v.Gap)
+	}
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
+)
+
+// writeSummary prints the system metrics as text or json.
+func (cmd *Command) writeSummary(out io.Writer, m pkggraph.SystemMetrics) error {
+	output := strings.ToLower(cmd.output)
+	switch {
+	case output == "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(m)
+	case record.IsText(output):
+	default:
+		return fmt.Errorf("unsupported output format %q for summary, expected text or json", cmd.output)
+	}
+
+	var w io.Writer = out
+	if !cmd.noAlign {
+		w = tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	}
+
+	fmt.Fprintf(w, "packages\t%d\n", m.Packages)
+	fmt.Fprintf(w, "CCD\t%d\n", m.CCD)
+	fmt.Fprintf(w, "ACD\t%.2f\n", m.ACD)
+	fmt.Fprintf(w, "NCCD\t%.2f\n", m.NCCD)
+	fmt.Fprintf(w, "propagation cost\t%.1f%%\n", 100*m.PropagationCost)
+
+	if w, ok := w.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+	return nil
+}
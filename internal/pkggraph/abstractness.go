@@ -0,0 +1,113 @@
+package pkggraph
+
+import (
+	"fmt"
+	"go/types"
+	"math"
+	"strings"
+)
+
+// AbstractnessOpts configures which declarations RefinedA considers.
+type AbstractnessOpts struct {
+	// ExportedOnly ignores unexported types.
+	ExportedOnly bool
+	// FuncTypes counts func types as abstract.
+	FuncTypes bool
+	// Constraints counts constraint interfaces, which have a type set,
+	// as abstract. By default they are ignored.
+	Constraints bool
+	// Aliases counts type aliases by their target type.
+	// By default they are ignored.
+	Aliases bool
+}
+
+// DefaultAbstractnessOpts are the options used when none are specified.
+var DefaultAbstractnessOpts = AbstractnessOpts{ExportedOnly: true}
+
+// ParseAbstractnessOpts parses a comma separated list of options:
+// all (include unexported types), func, constraints and aliases.
+func ParseAbstractnessOpts(spec string) (AbstractnessOpts, error) {
+	opts := DefaultAbstractnessOpts
+	for opt := range strings.SplitSeq(spec, ",") {
+		switch strings.TrimSpace(opt) {
+		case "":
+		case "exported":
+			opts.ExportedOnly = true
+		case "all":
+			opts.ExportedOnly = false
+		case "func":
+			opts.FuncTypes = true
+		case "constraints":
+			opts.Constraints = true
+		case "aliases":
+			opts.Aliases = true
+		default:
+			return opts, fmt.Errorf("unknown abstractness option %q, expected exported, all, func, constraints or aliases", opt)
+		}
+	}
+	return opts, nil
+}
+
+// typeCount is the number of abstract and all types counted for RefinedA.
+type typeCount struct {
+	Abstract int
+	Total    int
+}
+
+// ComputeRefinedAbstractness calculates RefinedA and RefinedD for each node
+// from the type checked package scope. Requires packages loaded with NeedTypes
+// and must be called after ComputeMetrics.
+func (g *Graph) ComputeRefinedAbstractness(opts AbstractnessOpts) {
+	for _, n := range g.Sorted {
+		if n.Package.Types == nil {
+			continue
+		}
+		n.typeCount = countTypes(n.Package.Types, opts)
+		n.computeRefinedDistance()
+	}
+}
+
+func countTypes(pkg *types.Package, opts AbstractnessOpts) typeCount {
+	var count typeCount
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if opts.ExportedOnly && !tn.Exported() {
+			continue
+		}
+		if tn.IsAlias() && !opts.Aliases {
+			continue
+		}
+
+		abstract := false
+		switch t := tn.Type().Underlying().(type) {
+		case *types.Interface:
+			if !t.IsMethodSet() && !opts.Constraints {
+				continue
+			}
+			abstract = true
+		case *types.Signature:
+			abstract = opts.FuncTypes
+		}
+
+		count.Total++
+		if abstract {
+			count.Abstract++
+		}
+	}
+
+	return count
+}
+
+// computeRefinedDistance computes RefinedA and RefinedD from the type counts and I.
+func (n *Node) computeRefinedDistance() {
+	n.RefinedA = 0
+	if n.typeCount.Total > 0 {
+		n.RefinedA = float64(n.typeCount.Abstract) / float64(n.typeCount.Total)
+	}
+	n.RefinedD = math.Abs(n.RefinedA + n.I - 1)
+}
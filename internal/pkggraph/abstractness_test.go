@@ -0,0 +1,47 @@
+package pkggraph
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestCountTypes(t *testing.T) {
+	const src = `package p
+
+type Reader interface{ Read([]byte) (int, error) }
+type Number interface{ ~int | ~float64 }
+type Handler func()
+type Config struct{}
+type Alias = Config
+type helper struct{}
+type local interface{ Close() error }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		opts AbstractnessOpts
+		want typeCount
+	}{
+		{AbstractnessOpts{ExportedOnly: true}, typeCount{Abstract: 1, Total: 3}},
+		{AbstractnessOpts{}, typeCount{Abstract: 2, Total: 5}},
+		{AbstractnessOpts{ExportedOnly: true, FuncTypes: true}, typeCount{Abstract: 2, Total: 3}},
+		{AbstractnessOpts{ExportedOnly: true, Constraints: true}, typeCount{Abstract: 2, Total: 4}},
+		{AbstractnessOpts{ExportedOnly: true, Aliases: true}, typeCount{Abstract: 1, Total: 4}},
+	}
+	for _, test := range tests {
+		if got := countTypes(pkg, test.opts); got != test.want {
+			t.Errorf("%+v: got %+v, want %+v", test.opts, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package pkggraph
+
+import (
+	"math"
+	"regexp"
+)
+
+const (
+	// pageRankDamping is the probability of following an import
+	// instead of jumping to a random package.
+	pageRankDamping = 0.85
+	// pageRankIterations limits the power iteration.
+	pageRankIterations = 100
+	// pageRankEpsilon stops the power iteration once the ranks converge.
+	pageRankEpsilon = 1e-10
+)
+
+// centralityFields matches the fields computed by ComputeCentrality.
+var centralityFields = regexp.MustCompile(`\b(PageRank|Betweenness|Depth|ReverseDepth)\b`)
+
+// UsesCentrality returns whether any of the templates or conditions refers
+// to a field computed by ComputeCentrality, which is expensive on large graphs.
+func UsesCentrality(texts ...string) bool {
+	for _, text := range texts {
+		if centralityFields.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeCentrality calculates PageRank, Betweenness, Depth and
+// ReverseDepth for every node from the imports of the graph.
+// Structural and co-change edges are not dependencies and are ignored.
+func (g *Graph) ComputeCentrality() {
+	index := make(map[*Node]int, len(g.Sorted))
+	for i, n := range g.Sorted {
+		index[n] = i
+	}
+	imports := make([][]int, len(g.Sorted))
+	for i, n := range g.Sorted {
+		for _, dep := range n.ImportsNodes {
+			if k, ok := index[dep]; ok {
+				imports[i] = append(imports[i], k)
+			}
+		}
+	}
+
+	for i, rank := range pageRank(imports) {
+		g.Sorted[i].PageRank = rank
+	}
+	for i, centrality := range betweenness(imports) {
+		g.Sorted[i].Betweenness = centrality
+	}
+
+	importers := make([][]int, len(g.Sorted))
+	for i, deps := range imports {
+		for _, k := range deps {
+			importers[k] = append(importers[k], i)
+		}
+	}
+	for i, depth := range longestPaths(imports) {
+		g.Sorted[i].Depth = depth
+	}
+	for i, depth := range longestPaths(importers) {
+		g.Sorted[i].ReverseDepth = depth
+	}
+}
+
+// pageRank computes the PageRank of nodes with edges from importers
+// to imports, so that rank flows towards depended-on packages.
+// Nodes without imports distribute their rank evenly to all nodes.
+func pageRank(imports [][]int) []float64 {
+	n := len(imports)
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	next := make([]float64, n)
+	for range pageRankIterations {
+		dangling := 0.0
+		for i, deps := range imports {
+			if len(deps) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i, deps := range imports {
+			share := pageRankDamping * rank[i] / float64(len(deps))
+			for _, k := range deps {
+				next[k] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < pageRankEpsilon {
+			break
+		}
+	}
+	return rank
+}
+
+// betweenness computes the betweenness centrality of nodes using
+// Brandes' algorithm for unweighted directed graphs: the number of
+// shortest paths between other nodes passing through the node.
+func betweenness(imports [][]int) []float64 {
+	n := len(imports)
+	centrality := make([]float64, n)
+
+	var (
+		stack        = make([]int, 0, n)
+		queue        = make([]int, 0, n)
+		predecessors = make([][]int, n)
+		paths        = make([]float64, n)
+		dist         = make([]int, n)
+		dependency   = make([]float64, n)
+	)
+
+	for source := range n {
+		stack, queue = stack[:0], queue[:0]
+		for i := range n {
+			predecessors[i] = predecessors[i][:0]
+			paths[i] = 0
+			dist[i] = -1
+			dependency[i] = 0
+		}
+		paths[source] = 1
+		dist[source] = 0
+		queue = append(queue, source)
+
+		for head := 0; head < len(queue); head++ {
+			v := queue[head]
+			stack = append(stack, v)
+			for _, w := range imports[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					paths[w] += paths[v]
+					predecessors[w] = append(predecessors[w], v)
+				}
+			}
+		}
+
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range predecessors[w] {
+				dependency[v] += paths[v] / paths[w] * (1 + dependency[w])
+			}
+			if w != source {
+				centrality[w] += dependency[w]
+			}
+		}
+	}
+	return centrality
+}
+
+// longestPaths returns the number of edges on the longest path from
+// each node to a node without edges. Edges closing a cycle are ignored.
+func longestPaths(edges [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	depth := make([]int, len(edges))
+	state := make([]int, len(edges))
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		for _, k := range edges[i] {
+			if state[k] == unvisited {
+				visit(k)
+			}
+			if state[k] == visited {
+				depth[i] = max(depth[i], depth[k]+1)
+			}
+		}
+		state[i] = visited
+	}
+	for i := range edges {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	return depth
+}
@@ -0,0 +1,93 @@
+package pkggraph
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeCentrality(t *testing.T) {
+	g := From(testPackages("a->b", "a->c", "b->d", "c->d", "d->e"))
+	g.ComputeCentrality()
+
+	tests := []struct {
+		id           string
+		betweenness  float64
+		depth        int
+		reverseDepth int
+	}{
+		{"a", 0, 3, 0},
+		{"b", 1, 2, 1},
+		{"c", 1, 2, 1},
+		{"d", 3, 1, 2},
+		{"e", 0, 0, 3},
+	}
+	for _, tt := range tests {
+		n := g.Packages[tt.id]
+		if math.Abs(n.Betweenness-tt.betweenness) > 1e-9 {
+			t.Errorf("%s: got betweenness %v, want %v", tt.id, n.Betweenness, tt.betweenness)
+		}
+		if n.Depth != tt.depth {
+			t.Errorf("%s: got depth %d, want %d", tt.id, n.Depth, tt.depth)
+		}
+		if n.ReverseDepth != tt.reverseDepth {
+			t.Errorf("%s: got reverse depth %d, want %d", tt.id, n.ReverseDepth, tt.reverseDepth)
+		}
+	}
+
+	total := 0.0
+	for _, n := range g.Sorted {
+		total += n.PageRank
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Errorf("got total PageRank %v, want 1", total)
+	}
+
+	p := func(id string) float64 { return g.Packages[id].PageRank }
+	if !(p("e") > p("d") && p("d") > p("b") && p("b") > p("a")) {
+		t.Errorf("PageRank should increase towards dependencies: a=%v b=%v d=%v e=%v", p("a"), p("b"), p("d"), p("e"))
+	}
+	if math.Abs(p("b")-p("c")) > 1e-9 {
+		t.Errorf("symmetric packages should have equal PageRank: b=%v c=%v", p("b"), p("c"))
+	}
+}
+
+func TestComputeCentralityCoChange(t *testing.T) {
+	g := From(testPackages("a->b", "c"))
+	g.AddCoChange(g.Packages["c"], g.Packages["a"], 5)
+	g.ComputeCentrality()
+
+	for id, depth := range map[string]int{"a": 1, "b": 0, "c": 0} {
+		if got := g.Packages[id].Depth; got != depth {
+			t.Errorf("%s: got depth %d, want %d", id, got, depth)
+		}
+	}
+	if got := g.Packages["a"].ReverseDepth; got != 0 {
+		t.Errorf("a: got reverse depth %d, want 0", got)
+	}
+}
+
+func TestLongestPathsCycle(t *testing.T) {
+	// a -> b -> c -> a, c -> d
+	got := longestPaths([][]int{{1}, {2}, {0, 3}, nil})
+	want := []int{3, 2, 1, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestUsesCentrality(t *testing.T) {
+	for text, want := range map[string]bool{
+		"{{.ID}} {{.PageRank}}":     true,
+		"ReverseDepth > 2":          true,
+		"Betweenness > 0 && Ca > 1": true,
+		"{{.ID}} {{.Ca}}":           false,
+		"DependsOn > 3":             false,
+	} {
+		if got := UsesCentrality("", text); got != want {
+			t.Errorf("UsesCentrality(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
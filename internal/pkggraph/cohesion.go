@@ -0,0 +1,171 @@
+package pkggraph
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cohesionFields matches the fields computed by ComputeCohesion.
+var cohesionFields = regexp.MustCompile(`\b(H|LCOM|Components)\b`)
+
+// UsesCohesion returns whether any of the templates or conditions refers
+// to a field computed by ComputeCohesion, which requires the syntax of
+// the packages and their dependencies.
+func UsesCohesion(texts ...string) bool {
+	for _, text := range texts {
+		if cohesionFields.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeCohesion calculates H, LCOM and Components for each node from the
+// references between the package level types and funcs of the package.
+// Methods are part of their receiver type. Requires packages loaded with
+// NeedSyntax and NeedTypesInfo.
+func (g *Graph) ComputeCohesion() {
+	for _, n := range g.Sorted {
+		if n.Package.Types == nil || n.Package.TypesInfo == nil {
+			continue
+		}
+		decls, relations := declRelations(n.Package)
+		n.computeCohesion(decls, relations)
+	}
+}
+
+// declRelation is a reference from one declaration to another.
+type declRelation struct{ from, to types.Object }
+
+// declRelations returns the package level types and funcs of p and the
+// distinct references between them.
+func declRelations(p *packages.Package) ([]types.Object, map[declRelation]bool) {
+	scope := p.Types.Scope()
+
+	var decls []types.Object
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.TypeName, *types.Func:
+			decls = append(decls, obj)
+		}
+	}
+
+	// declOf maps obj to the declaration it belongs to, nil when
+	// it's not a package level type or func of p.
+	declOf := func(obj types.Object) types.Object {
+		if obj == nil || obj.Pkg() != p.Types {
+			return nil
+		}
+		if fn, ok := obj.(*types.Func); ok {
+			if recv := fn.Signature().Recv(); recv != nil {
+				return receiverTypeName(recv.Type())
+			}
+		}
+		switch obj.(type) {
+		case *types.TypeName, *types.Func:
+			if obj.Parent() == scope {
+				return obj
+			}
+		}
+		return nil
+	}
+
+	relations := map[declRelation]bool{}
+	for _, file := range p.Syntax {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				owner := declOf(p.TypesInfo.Defs[decl.Name])
+				addRelations(p.TypesInfo, decl, owner, declOf, relations)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						owner := declOf(p.TypesInfo.Defs[spec.Name])
+						addRelations(p.TypesInfo, spec, owner, declOf, relations)
+					}
+				}
+			}
+		}
+	}
+	return decls, relations
+}
+
+// addRelations adds the references from owner to other declarations in node.
+func addRelations(info *types.Info, node ast.Node, owner types.Object, declOf func(types.Object) types.Object, relations map[declRelation]bool) {
+	if owner == nil {
+		return
+	}
+	ast.Inspect(node, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if target := declOf(info.Uses[ident]); target != nil && target != owner {
+			relations[declRelation{owner, target}] = true
+		}
+		return true
+	})
+}
+
+// receiverTypeName returns the named type of a method receiver.
+func receiverTypeName(t types.Type) types.Object {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	return nil
+}
+
+// computeCohesion computes the cohesion metrics from the declarations and their relations.
+func (n *Node) computeCohesion(decls []types.Object, relations map[declRelation]bool) {
+	n.H, n.LCOM, n.Components = 0, 0, 0
+	if len(decls) == 0 {
+		return
+	}
+
+	n.H = float64(len(relations)+1) / float64(len(decls))
+
+	index := make(map[types.Object]int, len(decls))
+	for i, decl := range decls {
+		index[decl] = i
+	}
+
+	// Union declarations that refer to each other in either direction.
+	parent := make([]int, len(decls))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type pair struct{ a, b int }
+	related := map[pair]bool{}
+	for r := range relations {
+		a, b := index[r.from], index[r.to]
+		if a > b {
+			a, b = b, a
+		}
+		related[pair{a, b}] = true
+		parent[find(a)] = find(b)
+	}
+
+	for i := range decls {
+		if find(i) == i {
+			n.Components++
+		}
+	}
+
+	if pairs := len(decls) * (len(decls) - 1) / 2; pairs > 0 {
+		n.LCOM = 1 - float64(len(related))/float64(pairs)
+	}
+}
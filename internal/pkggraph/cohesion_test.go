@@ -0,0 +1,63 @@
+package pkggraph
+
+import (
+	"go/token"
+	"math"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/testutil"
+)
+
+func TestComputeCohesion(t *testing.T) {
+	fset := token.NewFileSet()
+	p := testutil.CheckPackage(t, fset, "shop", `package shop
+type Cart struct{ items []Item }
+type Item struct{ price int }
+func (c *Cart) Total() int {
+	total := 0
+	for _, item := range c.items {
+		total += item.Price()
+	}
+	return total
+}
+func (i Item) Price() int { return i.price }
+func NewCart() *Cart { return &Cart{} }
+
+type Logger interface{ Log(msg string) }
+func Discard() Logger { return nil }
+
+func helper() int { return 1 }
+`)
+
+	g := From(map[string]*packages.Package{p.ID: p})
+	g.ComputeCohesion()
+	n := g.Packages["shop"]
+
+	// Declarations: Cart, Item, NewCart, Logger, Discard, helper.
+	// Relations: Cart->Item, NewCart->Cart, Discard->Logger.
+	if want := 4.0 / 6; math.Abs(n.H-want) > 1e-9 {
+		t.Errorf("got H %v, want %v", n.H, want)
+	}
+	if want := 1 - 3.0/15; math.Abs(n.LCOM-want) > 1e-9 {
+		t.Errorf("got LCOM %v, want %v", n.LCOM, want)
+	}
+	if n.Components != 3 {
+		t.Errorf("got %d components, want 3", n.Components)
+	}
+}
+
+func TestUsesCohesion(t *testing.T) {
+	for text, want := range map[string]bool{
+		"{{.ID}} {{printf \"%.2f\" .H}}": true,
+		"LCOM > 0.8":                     true,
+		"Components > 1 && Ca > 1":       true,
+		"{{.ID}} {{.Ca}}":                false,
+		"HasPrefix":                      false,
+	} {
+		if got := UsesCohesion("", text); got != want {
+			t.Errorf("UsesCohesion(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
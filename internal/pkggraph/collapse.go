@@ -0,0 +1,185 @@
+package pkggraph
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// GroupFunc returns the name of the group a node belongs to.
+// Empty string means the node is not part of any group.
+type GroupFunc func(n *Node) string
+
+// ParseGroup parses a grouping specification:
+//
+//	module  - group by module path
+//	dir=N   - group by the first N directories inside the module
+//	regexp  - group by the first submatch or the whole match of the regexp
+func ParseGroup(spec string) (GroupFunc, error) {
+	switch {
+	case spec == "module" || spec == "mod":
+		return ByModule, nil
+	case strings.HasPrefix(spec, "dir="):
+		depth, err := strconv.Atoi(strings.TrimPrefix(spec, "dir="))
+		if err != nil || depth < 0 {
+			return nil, fmt.Errorf("invalid directory depth in %q", spec)
+		}
+		return ByDir(depth), nil
+	default:
+		rx, err := regexp.Compile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group regexp %q: %w", spec, err)
+		}
+		return ByRegexp(rx), nil
+	}
+}
+
+// ByModule groups nodes by their module path.
+// Packages without a module are grouped as "std", when they look like
+// a standard library package.
+func ByModule(n *Node) string {
+	if n.Module != nil {
+		return n.Module.Path
+	}
+	if isStdPath(n.PkgPath) {
+		return "std"
+	}
+	return n.PkgPath
+}
+
+// ByDir groups nodes by the first depth directories relative to their module.
+func ByDir(depth int) GroupFunc {
+	return func(n *Node) string {
+		root, rel := "", n.PkgPath
+		if n.Module != nil {
+			root = n.Module.Path
+			rel = strings.TrimPrefix(strings.TrimPrefix(rel, root), "/")
+		}
+
+		dirs := strings.Split(rel, "/")
+		if rel == "" {
+			dirs = nil
+		}
+		if len(dirs) > depth {
+			dirs = dirs[:depth]
+		}
+
+		if root == "" {
+			return strings.Join(dirs, "/")
+		}
+		return strings.Join(append([]string{root}, dirs...), "/")
+	}
+}
+
+// ByRegexp groups nodes by the first submatch of rx in the package path,
+// or the whole match when rx has no submatches.
+func ByRegexp(rx *regexp.Regexp) GroupFunc {
+	return func(n *Node) string {
+		match := rx.FindStringSubmatch(n.PkgPath)
+		switch {
+		case match == nil:
+			return ""
+		case len(match) > 1:
+			return match[1]
+		default:
+			return match[0]
+		}
+	}
+}
+
+// Collapse merges nodes into group nodes as specified by group.
+//
+// The group nodes contain the summed stats of their members and
+// edges between groups are weighted by the number of package imports
+// between the members. Nodes without a group and stub nodes are kept as is.
+func (g *Graph) Collapse(group GroupFunc) *Graph {
+	collapsed := &Graph{
+		Packages:        map[string]*Node{},
+		Implementations: g.Implementations,
+	}
+
+	byNode := map[*Node]*Node{}
+	for _, n := range g.Sorted {
+		key := ""
+		if n.Stub == 0 {
+			key = group(n)
+		}
+		if key == "" {
+			key = n.ID
+		}
+
+		target, ok := collapsed.Packages[key]
+		if !ok {
+			target = &Node{
+				Package: &packages.Package{
+					ID:      key,
+					Name:    n.Name,
+					PkgPath: key,
+					Module:  n.Module,
+				},
+				Color: n.Color,
+				Stub:  n.Stub,
+			}
+			collapsed.Sorted = append(collapsed.Sorted, target)
+			collapsed.AddNode(target)
+		}
+
+		target.Members = append(target.Members, n)
+		target.Stat.Add(n.Stat)
+		target.SetCoverage(target.Statements+n.Statements, target.CoveredStatements+n.CoveredStatements)
+		target.Errors = append(target.Errors, n.Errors...)
+		target.ImportSpecs = append(target.ImportSpecs, n.ImportSpecs...)
+		if target.Module != nil && (n.Module == nil || n.Module.Path != target.Module.Path) {
+			target.Module = nil
+		}
+		if target.Color != n.Color {
+			target.Color = ""
+		}
+		if target.Name != n.Name {
+			target.Name = ""
+		}
+
+		collapsed.Stat.Add(n.Stat)
+		byNode[n] = target
+	}
+
+	for _, n := range g.Sorted {
+		src := byNode[n]
+		for _, e := range n.Edges {
+			dst := byNode[e.To]
+			if src == dst {
+				continue
+			}
+			link := src.link(dst)
+			if link.Weight == 0 || e.Hidden < link.Hidden {
+				link.Hidden = e.Hidden
+			}
+			link.Weight += e.Weight
+			link.Imports = append(link.Imports, e.Imports...)
+			link.Implementations = append(link.Implementations, e.Implementations...)
+			link.CoChanges += e.CoChanges
+		}
+	}
+
+	SortNodes(collapsed.Sorted)
+	for _, n := range collapsed.Sorted {
+		n.sortImports()
+	}
+	collapsed.computeDependsOnFromEdges()
+
+	return collapsed
+}
+
+// isStdPath reports whether path looks like a standard library package.
+func isStdPath(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return path != "" && !strings.Contains(first, ".")
+}
+
+// isVendorPath reports whether path is a vendored package.
+func isVendorPath(path string) bool {
+	return strings.HasPrefix(path, "vendor/") || strings.Contains(path, "/vendor/")
+}
@@ -0,0 +1,54 @@
+package pkggraph
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCollapse(t *testing.T) {
+	g := From(testPackages("x/a->x/b", "x/a->y/c", "x/b->y/c", "x/b->y/d", "y/c->y/d"))
+
+	collapsed := g.Collapse(ByRegexp(regexp.MustCompile(`^[^/]+`)))
+
+	var got []string
+	for _, n := range collapsed.Sorted {
+		for _, e := range n.Edges {
+			got = append(got, n.ID+"->"+e.To.ID)
+			if e.Weight != 3 {
+				t.Errorf("%v->%v: got weight %d, want 3", n.ID, e.To.ID, e.Weight)
+			}
+		}
+	}
+	if want := []string{"x->y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if n := len(collapsed.Packages["y"].Members); n != 2 {
+		t.Errorf("got %d members, want 2", n)
+	}
+	if n := collapsed.Packages["x"].Stat.PackageCount; n != 2 {
+		t.Errorf("got package count %d, want 2", n)
+	}
+}
+
+func TestByDir(t *testing.T) {
+	g := From(testPackages("a/b/c/d", "a"))
+
+	tests := []struct {
+		depth int
+		id    string
+		want  string
+	}{
+		{0, "a/b/c/d", ""},
+		{1, "a/b/c/d", "a"},
+		{2, "a/b/c/d", "a/b"},
+		{8, "a/b/c/d", "a/b/c/d"},
+		{2, "a", "a"},
+	}
+	for _, test := range tests {
+		if got := ByDir(test.depth)(g.Packages[test.id]); got != test.want {
+			t.Errorf("ByDir(%d)(%q) = %q, want %q", test.depth, test.id, got, test.want)
+		}
+	}
+}
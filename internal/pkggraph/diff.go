@@ -0,0 +1,99 @@
+package pkggraph
+
+// Change describes how a node or an edge differs between two graphs.
+type Change int
+
+const (
+	// Unchanged means the node or edge exists in both graphs.
+	Unchanged Change = iota
+	// Added means the node or edge exists only in the new graph.
+	Added
+	// Removed means the node or edge exists only in the old graph.
+	Removed
+)
+
+// String implements fmt.Stringer.
+func (c Change) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "unchanged"
+	}
+}
+
+// Diff merges old and new into a single graph, where every node
+// and edge is marked whether it was added, removed or unchanged.
+// Nodes are matched by their ID.
+func Diff(old, new *Graph) *Graph {
+	merged := &Graph{Packages: map[string]*Node{}}
+
+	add := func(n *Node, change Change) {
+		clone := *n
+		clone.ImportsNodes = nil
+		clone.Edges = nil
+		clone.Change = change
+
+		merged.Sorted = append(merged.Sorted, &clone)
+		merged.AddNode(&clone)
+		merged.Stat.Add(clone.Stat)
+	}
+
+	for _, n := range new.Sorted {
+		if _, ok := old.Packages[n.ID]; ok {
+			add(n, Unchanged)
+		} else {
+			add(n, Added)
+		}
+	}
+	for _, n := range old.Sorted {
+		if _, ok := new.Packages[n.ID]; !ok {
+			add(n, Removed)
+		}
+	}
+
+	for _, n := range new.Sorted {
+		src := merged.Packages[n.ID]
+		prev := old.Packages[n.ID]
+		for _, e := range n.Edges {
+			link := src.link(merged.Packages[e.To.ID])
+			link.Weight, link.Hidden, link.Imports = e.Weight, e.Hidden, e.Imports
+			link.Implementations = e.Implementations
+			if prev == nil || !prev.importsID(e.To.ID) {
+				link.Change = Added
+			}
+		}
+	}
+	for _, n := range old.Sorted {
+		src := merged.Packages[n.ID]
+		next := new.Packages[n.ID]
+		for _, e := range n.Edges {
+			if next != nil && next.importsID(e.To.ID) {
+				continue
+			}
+			link := src.link(merged.Packages[e.To.ID])
+			link.Weight, link.Hidden, link.Imports = e.Weight, e.Hidden, e.Imports
+			link.Implementations = e.Implementations
+			link.Change = Removed
+		}
+	}
+
+	SortNodes(merged.Sorted)
+	for _, n := range merged.Sorted {
+		n.sortImports()
+	}
+
+	return merged
+}
+
+// importsID returns whether n has an edge to a node with the specified id.
+func (n *Node) importsID(id string) bool {
+	for _, e := range n.Edges {
+		if e.To.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package pkggraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := From(testPackages("a->b", "b->c", "a->d"))
+	new := From(testPackages("a->b", "b->c", "b->e", "a->c"))
+
+	diff := Diff(old, new)
+
+	var got []string
+	for _, n := range diff.Sorted {
+		got = append(got, n.ID+" "+n.Change.String())
+		for _, e := range n.Edges {
+			got = append(got, n.ID+"->"+e.To.ID+" "+e.Change.String())
+		}
+	}
+
+	want := []string{
+		"a unchanged",
+		"a->b unchanged",
+		"a->c added",
+		"a->d removed",
+		"b unchanged",
+		"b->c unchanged",
+		"b->e added",
+		"c unchanged",
+		"d removed",
+		"e added",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
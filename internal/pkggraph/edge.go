@@ -0,0 +1,151 @@
+package pkggraph
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// Edge is a dependency from one node to another.
+type Edge struct {
+	From *Node
+	To   *Node
+
+	// Color overrides the color of the edge.
+	Color string
+	// Change is set by Diff.
+	Change Change
+
+	// Weight is the number of package imports the edge represents,
+	// which is larger than one only for collapsed graphs.
+	Weight int
+
+	// Hidden is the number of packages excluded from the graph between
+	// From and To. It is zero for direct imports.
+	Hidden int
+
+	// Imports are the import specs in From that create this edge.
+	Imports []stat.Import
+
+	// Implementations are the types in From satisfying interfaces in To,
+	// for structural edges added by AddStructuralEdges.
+	Implementations []Implementation
+
+	// CoChanges is the number of commits changing both From and To,
+	// added by AddCoChange.
+	CoChanges int
+}
+
+// Structural returns whether the edge represents only structural coupling,
+// without any imports.
+func (e *Edge) Structural() bool {
+	return len(e.Implementations) > 0 && e.Weight == 0
+}
+
+// CoChangeOnly returns whether the edge represents only packages changing
+// together, without any imports or structural coupling.
+func (e *Edge) CoChangeOnly() bool {
+	return e.CoChanges > 0 && e.Weight == 0 && len(e.Implementations) == 0
+}
+
+// Files returns the files that contain the imports, in sorted order.
+func (e *Edge) Files() []string {
+	var files []string
+	for _, imp := range e.Imports {
+		if !slices.Contains(files, imp.File) {
+			files = append(files, imp.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// TestOnly returns whether the edge exists only in _test.go files.
+func (e *Edge) TestOnly() bool {
+	return len(e.Imports) > 0 && !slices.ContainsFunc(e.Imports, func(imp stat.Import) bool { return !imp.Test })
+}
+
+// Blank returns whether all imports are blank imports for side effects.
+func (e *Edge) Blank() bool {
+	return len(e.Imports) > 0 && !slices.ContainsFunc(e.Imports, func(imp stat.Import) bool { return !imp.Blank() })
+}
+
+// Dot returns whether any of the imports is a dot import.
+func (e *Edge) Dot() bool {
+	return slices.ContainsFunc(e.Imports, stat.Import.Dot)
+}
+
+// Aliased returns whether any of the imports uses a different package name.
+func (e *Edge) Aliased() bool {
+	return slices.ContainsFunc(e.Imports, stat.Import.Aliased)
+}
+
+// Conditional returns whether the edge exists only under build constraints.
+func (e *Edge) Conditional() bool {
+	return len(e.Imports) > 0 && !slices.ContainsFunc(e.Imports, func(imp stat.Import) bool { return imp.Constraint == "" })
+}
+
+// Constraints returns the distinct build constraints of the importing files.
+func (e *Edge) Constraints() []string {
+	var constraints []string
+	for _, imp := range e.Imports {
+		if imp.Constraint != "" && !slices.Contains(constraints, imp.Constraint) {
+			constraints = append(constraints, imp.Constraint)
+		}
+	}
+	sort.Strings(constraints)
+	return constraints
+}
+
+// EdgeTo returns the edge from n to dst, or nil when there is none.
+func (n *Node) EdgeTo(dst *Node) *Edge {
+	for _, e := range n.Edges {
+		if e.To == dst {
+			return e
+		}
+	}
+	return nil
+}
+
+// AddCoChange records that from and to changed together in commits.
+// The count is added to an existing edge in either direction,
+// otherwise a new edge from from to to is added, which is not added
+// to ImportsNodes.
+func (g *Graph) AddCoChange(from, to *Node, commits int) *Edge {
+	e := from.EdgeTo(to)
+	if e == nil {
+		e = to.EdgeTo(from)
+	}
+	if e == nil {
+		e = from.link(to)
+		from.sortImports()
+	}
+	e.CoChanges += commits
+	return e
+}
+
+// link adds an edge from n to dst, when it doesn't exist yet.
+func (n *Node) link(dst *Node) *Edge {
+	if e := n.EdgeTo(dst); e != nil {
+		return e
+	}
+	e := &Edge{From: n, To: dst}
+	n.Edges = append(n.Edges, e)
+	return e
+}
+
+// sortImports sorts Edges by the target ID and sets ImportsNodes
+// to the targets of the edges representing imports.
+//
+// Edges added only by AddStructuralEdges or AddCoChange have no weight
+// and are left out of ImportsNodes.
+func (n *Node) sortImports() {
+	sort.Slice(n.Edges, func(i, k int) bool { return n.Edges[i].To.ID < n.Edges[k].To.ID })
+	n.ImportsNodes = nil
+	for _, e := range n.Edges {
+		if e.Weight > 0 {
+			n.ImportsNodes = append(n.ImportsNodes, e.To)
+		}
+	}
+}
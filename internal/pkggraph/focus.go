@@ -0,0 +1,173 @@
+package pkggraph
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Direction specifies which edges to follow when walking the graph.
+type Direction int
+
+const (
+	// Down follows imports.
+	Down Direction = 1 << iota
+	// Up follows importers.
+	Up
+	// Both follows imports and importers.
+	Both = Down | Up
+)
+
+// ParseDirection parses "up", "down" or "both".
+func ParseDirection(s string) (Direction, error) {
+	switch s {
+	case "down":
+		return Down, nil
+	case "up":
+		return Up, nil
+	case "both":
+		return Both, nil
+	default:
+		return 0, fmt.Errorf("unknown direction %q, expected up, down or both", s)
+	}
+}
+
+// Neighborhood returns nodes that are within radius hops from roots,
+// mapped to their distance from the closest root.
+// Negative radius means there is no limit.
+func (g *Graph) Neighborhood(roots []*Node, radius int, dir Direction) map[*Node]int {
+	importers := g.importers()
+
+	dist := map[*Node]int{}
+	queue := []*Node{}
+	for _, root := range roots {
+		if _, ok := dist[root]; ok {
+			continue
+		}
+		dist[root] = 0
+		queue = append(queue, root)
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		d := dist[n]
+		if radius >= 0 && d >= radius {
+			continue
+		}
+
+		visit := func(next *Node) {
+			if _, ok := dist[next]; ok {
+				return
+			}
+			dist[next] = d + 1
+			queue = append(queue, next)
+		}
+		if dir&Down != 0 {
+			for _, imp := range n.ImportsNodes {
+				visit(imp)
+			}
+		}
+		if dir&Up != 0 {
+			for _, imp := range importers[n] {
+				visit(imp)
+			}
+		}
+	}
+
+	return dist
+}
+
+// Focus returns a new graph that contains only the nodes within radius hops
+// from roots. Nodes whose edges were truncated get a stub node that
+// summarizes the number of elided packages.
+func (g *Graph) Focus(roots []*Node, radius int, dir Direction) *Graph {
+	dist := g.Neighborhood(roots, radius, dir)
+	importers := g.importers()
+
+	focus := &Graph{Packages: map[string]*Node{}}
+
+	clones := map[*Node]*Node{}
+	for _, n := range g.Sorted {
+		if _, ok := dist[n]; !ok {
+			continue
+		}
+		clone := *n
+		clone.ImportsNodes = nil
+		clone.Edges = nil
+		clones[n] = &clone
+
+		focus.Sorted = append(focus.Sorted, &clone)
+		focus.AddNode(&clone)
+		focus.Stat.Add(clone.Stat)
+	}
+
+	for _, n := range g.Sorted {
+		clone, ok := clones[n]
+		if !ok {
+			continue
+		}
+
+		elidedImports := 0
+		for _, e := range n.Edges {
+			if dst, ok := clones[e.To]; ok {
+				link := clone.link(dst)
+				link.Weight, link.Hidden, link.Imports = e.Weight, e.Hidden, e.Imports
+				link.Implementations = e.Implementations
+				link.CoChanges = e.CoChanges
+			} else if e.Weight > 0 {
+				elidedImports++
+			}
+		}
+
+		elidedImporters := 0
+		for _, imp := range importers[n] {
+			if _, ok := clones[imp]; !ok {
+				elidedImporters++
+			}
+		}
+
+		if dir&Down != 0 && elidedImports > 0 {
+			stub := newStub(n.ID+" imports", elidedImports)
+			focus.Sorted = append(focus.Sorted, stub)
+			focus.AddNode(stub)
+			clone.link(stub).Weight = 1
+		}
+		if dir&Up != 0 && elidedImporters > 0 {
+			stub := newStub(n.ID+" importers", elidedImporters)
+			focus.Sorted = append(focus.Sorted, stub)
+			focus.AddNode(stub)
+			stub.link(clone).Weight = 1
+		}
+	}
+
+	SortNodes(focus.Sorted)
+	for _, n := range focus.Sorted {
+		n.sortImports()
+	}
+
+	return focus
+}
+
+// newStub creates a placeholder node for count elided packages.
+func newStub(id string, count int) *Node {
+	return &Node{
+		Package: &packages.Package{
+			ID:   fmt.Sprintf("%s (+%d more)", id, count),
+			Name: "stub",
+		},
+		Stub: count,
+	}
+}
+
+// importers returns the reverse of ImportsNodes.
+func (g *Graph) importers() map[*Node][]*Node {
+	importers := map[*Node][]*Node{}
+	for _, n := range g.Sorted {
+		for _, imp := range n.ImportsNodes {
+			importers[imp] = append(importers[imp], n)
+		}
+	}
+	return importers
+}
@@ -0,0 +1,70 @@
+package pkggraph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// testPackages creates packages from "importer->imported" pairs.
+func testPackages(edges ...string) map[string]*packages.Package {
+	pkgs := map[string]*packages.Package{}
+	get := func(id string) *packages.Package {
+		p, ok := pkgs[id]
+		if !ok {
+			p = &packages.Package{ID: id, PkgPath: id, Name: id, Imports: map[string]*packages.Package{}}
+			pkgs[id] = p
+		}
+		return p
+	}
+	for _, edge := range edges {
+		from, to, ok := strings.Cut(edge, "->")
+		if !ok {
+			get(edge)
+			continue
+		}
+		src, dst := get(from), get(to)
+		src.Imports[dst.PkgPath] = dst
+	}
+	return pkgs
+}
+
+func edgeList(g *Graph) []string {
+	var xs []string
+	for _, n := range g.Sorted {
+		for _, imp := range n.ImportsNodes {
+			xs = append(xs, n.ID+"->"+imp.ID)
+		}
+	}
+	return xs
+}
+
+func TestFocus(t *testing.T) {
+	g := From(testPackages("a->b", "b->c", "c->d", "e->b", "f->e"))
+
+	tests := []struct {
+		dir    Direction
+		radius int
+		want   []string
+	}{
+		{Down, 1, []string{"b->c", "c->c imports (+1 more)"}},
+		{Up, 1, []string{"a->b", "e->b", "e importers (+1 more)->e"}},
+		{Both, 1, []string{
+			"a->b", "b->c",
+			"c->c imports (+1 more)",
+			"e->b",
+			"e importers (+1 more)->e",
+		}},
+		{Both, -1, []string{"a->b", "b->c", "c->d", "e->b", "f->e"}},
+	}
+
+	for _, test := range tests {
+		focus := g.Focus([]*Node{g.Packages["b"]}, test.radius, test.dir)
+		got := edgeList(focus)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("dir=%v radius=%v\ngot:  %q\nwant: %q", test.dir, test.radius, got, test.want)
+		}
+	}
+}
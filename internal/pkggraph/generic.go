@@ -0,0 +1,137 @@
+package pkggraph
+
+import (
+	"go/types"
+)
+
+// genericImplements reports whether some instantiation of t implements iface,
+// where t or iface may have type parameters. The method signatures are
+// compared modulo the type parameters of tparams and ifaceTParams, which must
+// be bound consistently across all methods and satisfy their constraints.
+func genericImplements(t types.Type, iface *types.Interface, tparams, ifaceTParams *types.TypeParamList) bool {
+	mset := types.NewMethodSet(t)
+	m := &matcher{
+		params: [2]*types.TypeParamList{ifaceTParams, tparams},
+		bound:  map[paramKey]types.Type{},
+	}
+	for i := range iface.NumMethods() {
+		method := iface.Method(i)
+		sel := mset.Lookup(method.Pkg(), method.Name())
+		if sel == nil {
+			return false
+		}
+		if !m.match(method.Type(), sel.Obj().Type()) {
+			return false
+		}
+	}
+	return m.satisfied()
+}
+
+// Sides of the comparison in matcher.
+const (
+	ifaceSide = iota
+	typeSide
+)
+
+type paramKey struct {
+	side  int
+	index int
+}
+
+// matcher compares types from the interface side with types from the
+// concrete type side, binding type parameters of either side.
+type matcher struct {
+	params [2]*types.TypeParamList
+	bound  map[paramKey]types.Type
+}
+
+func (m *matcher) bind(side int, tp *types.TypeParam, t types.Type) bool {
+	key := paramKey{side: side, index: tp.Index()}
+	if prev, ok := m.bound[key]; ok {
+		return types.Identical(prev, t)
+	}
+	m.bound[key] = t
+	return true
+}
+
+// satisfied reports whether the bound types satisfy the constraints.
+func (m *matcher) satisfied() bool {
+	for key, t := range m.bound {
+		list := m.params[key.side]
+		if list == nil || key.index >= list.Len() {
+			continue
+		}
+		if _, ok := t.(*types.TypeParam); ok {
+			continue
+		}
+		constraint, ok := list.At(key.index).Constraint().Underlying().(*types.Interface)
+		if ok && !types.Satisfies(t, constraint) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether x from the interface side and y from the type side
+// are identical, after binding the type parameters.
+func (m *matcher) match(x, y types.Type) bool {
+	x, y = types.Unalias(x), types.Unalias(y)
+	if tp, ok := x.(*types.TypeParam); ok {
+		return m.bind(ifaceSide, tp, y)
+	}
+	if tp, ok := y.(*types.TypeParam); ok {
+		return m.bind(typeSide, tp, x)
+	}
+
+	switch x := x.(type) {
+	case *types.Pointer:
+		y, ok := y.(*types.Pointer)
+		return ok && m.match(x.Elem(), y.Elem())
+	case *types.Slice:
+		y, ok := y.(*types.Slice)
+		return ok && m.match(x.Elem(), y.Elem())
+	case *types.Array:
+		y, ok := y.(*types.Array)
+		return ok && x.Len() == y.Len() && m.match(x.Elem(), y.Elem())
+	case *types.Map:
+		y, ok := y.(*types.Map)
+		return ok && m.match(x.Key(), y.Key()) && m.match(x.Elem(), y.Elem())
+	case *types.Chan:
+		y, ok := y.(*types.Chan)
+		return ok && x.Dir() == y.Dir() && m.match(x.Elem(), y.Elem())
+	case *types.Signature:
+		y, ok := y.(*types.Signature)
+		return ok && x.Variadic() == y.Variadic() &&
+			m.matchTuple(x.Params(), y.Params()) &&
+			m.matchTuple(x.Results(), y.Results())
+	case *types.Named:
+		y, ok := y.(*types.Named)
+		if !ok || x.Origin().Obj() != y.Origin().Obj() {
+			return false
+		}
+		xargs, yargs := x.TypeArgs(), y.TypeArgs()
+		if xargs.Len() != yargs.Len() {
+			return false
+		}
+		for i := range xargs.Len() {
+			if !m.match(xargs.At(i), yargs.At(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return types.Identical(x, y)
+	}
+}
+
+func (m *matcher) matchTuple(x, y *types.Tuple) bool {
+	if x.Len() != y.Len() {
+		return false
+	}
+	for i := range x.Len() {
+		if !m.match(x.At(i).Type(), y.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,13 +1,15 @@
 package pkggraph
 
 import (
+	"context"
 	"encoding/json"
-	"go/types"
+	"fmt"
 	"math"
 	"sort"
 
 	"golang.org/x/tools/go/packages"
 
+	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
 	"github.com/flamingoosesoftwareinc/goda/internal/stat"
 )
 
@@ -15,6 +17,9 @@ type Graph struct {
 	Packages map[string]*Node
 	Sorted   []*Node
 	stat.Stat
+
+	// Implementations are the structural couplings found by ComputeStructuralCoupling.
+	Implementations []Implementation
 }
 
 func (g *Graph) AddNode(n *Node) {
@@ -26,7 +31,24 @@ type Node struct {
 	*packages.Package
 	Color string
 
+	// Change is set by Diff.
+	Change Change
+
+	// Stub is the number of elided packages this placeholder node stands for,
+	// zero for regular nodes.
+	Stub int
+
+	// ImportsNodes are the imported nodes, sorted by ID.
 	ImportsNodes []*Node
+	// Edges to ImportsNodes and the structural and co-change edges,
+	// sorted by the target ID.
+	Edges []*Edge
+
+	// Members are the nodes merged into this node by Collapse.
+	Members []*Node
+
+	// ImportSpecs are the import specs in the Go files of the package.
+	ImportSpecs []stat.Import
 
 	// Stats about the current node.
 	stat.Stat
@@ -46,14 +68,80 @@ type Node struct {
 	SCa float64 // Structural afferent coupling: packages with types satisfying this package's interfaces, excluding importers.
 	SCe float64 // Structural efferent coupling: packages with interfaces satisfied by this package's types, excluding imports.
 
+	// Abstractness computed from go/types (requires -types flag).
+	RefinedA float64 // Ratio of abstract types, as configured by AbstractnessOpts.
+	RefinedD float64 // Distance from the main sequence using RefinedA: |RefinedA + I - 1|.
+
+	// Cohesion metrics computed by ComputeCohesion (requires -types flag).
+	H          float64 // Relational cohesion: (R + 1) / N for R references between N types and funcs.
+	LCOM       float64 // Lack of cohesion: ratio of type and func pairs without references between them.
+	Components int     // Connected components of types and funcs, more than one suggests a split.
+
+	// Centrality metrics computed by ComputeCentrality.
+	PageRank     float64 // PageRank with rank flowing from importers to imports.
+	Betweenness  float64 // Number of shortest paths between other packages through this package.
+	Depth        int     // Longest path to a package without imports.
+	ReverseDepth int     // Longest path from a package without importers.
+
+	// Test coverage set by SetCoverage (requires -coverprofile flag).
+	Coverage          float64 // Percentage of covered statements.
+	Statements        int     // Statements in the coverage profile, zero when the package is not in it.
+	CoveredStatements int     // Statements executed by tests.
+
+	// DependsOn is the number of packages in the graph this package
+	// depends on directly or indirectly, including itself (Lakos).
+	DependsOn int
+
+	typeCount typeCount
+
+	// satisfies are the packages with interfaces satisfied by this package's types.
+	satisfies []*Node
+
 	Errors []error
 	Graph  *Graph
 }
 
 func (n *Node) Pkg() *packages.Package { return n.Package }
 
+// SetCoverage sets Statements, CoveredStatements and the resulting Coverage.
+func (n *Node) SetCoverage(statements, covered int) {
+	n.Statements, n.CoveredStatements = statements, covered
+	n.Coverage = 0
+	if statements > 0 {
+		n.Coverage = 100 * float64(covered) / float64(statements)
+	}
+}
+
+// CoverageColor returns a color blended from red for uncovered to green
+// for fully covered packages, gray for packages without coverage data.
+func (n *Node) CoverageColor() string {
+	if n.Statements == 0 {
+		return "#bab0ac"
+	}
+	const (
+		r0, g0, b0 = 0xe1, 0x57, 0x59
+		r1, g1, b1 = 0x59, 0xa1, 0x4f
+	)
+	t := n.Coverage / 100
+	blend := func(a, b int) int { return a + int(math.Round(t*float64(b-a))) }
+	return fmt.Sprintf("#%02x%02x%02x", blend(r0, r1), blend(g0, g1), blend(b0, b1))
+}
+
+// FromOpts configures optional behaviors for FromWithOpts.
+type FromOpts struct {
+	// HiddenEdges adds edges between packages that depend on each other
+	// only through packages that are not part of the graph.
+	HiddenEdges bool
+}
+
 // From creates a new graph from a map of packages.
 func From(pkgs map[string]*packages.Package) *Graph {
+	return FromWithOpts(pkgs, FromOpts{})
+}
+
+// FromWithOpts creates a new graph from a map of packages,
+// with additional options.
+func FromWithOpts(pkgs map[string]*packages.Package, opts FromOpts) *Graph {
 	g := &Graph{Packages: map[string]*Node{}}
 
 	// Create the graph nodes.
@@ -69,6 +157,8 @@ func From(pkgs map[string]*packages.Package) *Graph {
 
 	cache := allImportsCache(pkgs)
 
+	g.computeDependsOn(cache)
+
 	// Populate the graph's Up and Down stats.
 	for _, n := range g.Packages {
 		importsIDs := cache[n.ID]
@@ -89,18 +179,22 @@ func From(pkgs map[string]*packages.Package) *Graph {
 		for id := range n.Package.Imports {
 			direct, ok := g.Packages[id]
 			if !ok {
-				// TODO:
-				//  should we include dependencies where Y is hidden?
-				//  X -> [Y] -> Z
+				// X -> [Y] -> Z edges are added by addHiddenEdges.
 				continue
 			}
 
-			n.ImportsNodes = append(n.ImportsNodes, direct)
+			e := n.link(direct)
+			e.Weight = 1
+			e.Imports = n.importSpecsOf(id)
 		}
 	}
 
+	if opts.HiddenEdges {
+		g.addHiddenEdges()
+	}
+
 	for _, n := range g.Packages {
-		SortNodes(n.ImportsNodes)
+		n.sortImports()
 	}
 
 	return g
@@ -110,11 +204,69 @@ func From(pkgs map[string]*packages.Package) *Graph {
 // for every node in the graph. allPkgs is the full set of loaded packages used
 // to determine afferent couplings from packages that may be outside the graph.
 func (g *Graph) ComputeMetrics(allPkgs map[string]*packages.Package) {
+	g.ComputeMetricsWithOpts(allPkgs, MetricsOpts{})
+}
+
+// MetricsOpts configures which packages count towards couplings.
+type MetricsOpts struct {
+	// Scope restricts couplings to packages in the scope, when not nil.
+	Scope map[string]*packages.Package
+	// ExcludeStd excludes std imports from Ce.
+	ExcludeStd bool
+	// ExcludeVendor excludes vendored imports from Ce.
+	ExcludeVendor bool
+}
+
+// ParseScope evaluates a -scope flag for MetricsOpts.Scope: "" for all
+// loaded packages, "analyzed" for the analyzed packages, or otherwise
+// a package expression evaluated in dir.
+func ParseScope(ctx context.Context, scope, dir string, analyzed map[string]*packages.Package) (map[string]*packages.Package, error) {
+	switch scope {
+	case "":
+		return nil, nil
+	case "analyzed":
+		return analyzed, nil
+	default:
+		set, err := pkgset.CalcWithOpts(ctx, []string{scope}, pkgset.CalcOpts{Dir: dir})
+		if err != nil {
+			return nil, fmt.Errorf("invalid scope: %w", err)
+		}
+		return set, nil
+	}
+}
+
+// importers returns the packages that count as importers for Ca.
+func (opts *MetricsOpts) importers(allPkgs map[string]*packages.Package) map[string]*packages.Package {
+	if opts.Scope != nil {
+		return opts.Scope
+	}
+	return allPkgs
+}
+
+// efferent returns whether an import of p counts towards Ce.
+func (opts *MetricsOpts) efferent(p *packages.Package) bool {
+	if opts.Scope != nil && opts.Scope[p.ID] == nil {
+		return false
+	}
+	if opts.ExcludeStd && isStdPath(p.PkgPath) {
+		return false
+	}
+	if opts.ExcludeVendor && isVendorPath(p.PkgPath) {
+		return false
+	}
+	return true
+}
+
+// ComputeMetricsWithOpts is ComputeMetrics, where opts restrict the packages
+// counted as couplings.
+func (g *Graph) ComputeMetricsWithOpts(allPkgs map[string]*packages.Package, opts MetricsOpts) {
+	importers := opts.importers(allPkgs)
+
 	// Compute Ca: for each package in the graph, count how many packages
-	// across allPkgs import it.
+	// across the importers import it.
 	for _, n := range g.Packages {
 		var ca int
-		for _, p := range allPkgs {
+		for _, p := range importers {
 			if p.ID == n.ID {
 				continue
 			}
@@ -127,132 +279,60 @@ func (g *Graph) ComputeMetrics(allPkgs map[string]*packages.Package) {
 
 	// Compute Ce: number of direct imports for each node.
 	for _, n := range g.Packages {
-		n.Ce = float64(len(n.Package.Imports))
+		var ce int
+		for _, imp := range n.Package.Imports {
+			if opts.efferent(imp) {
+				ce++
+			}
+		}
+		n.Ce = float64(ce)
 	}
 
 	// Compute A, I, D.
 	for _, n := range g.Packages {
-		totalTypes := n.Stat.Decls.Type
-		interfaces := n.Stat.Decls.Interface
-		if totalTypes > 0 {
-			n.A = float64(interfaces) / float64(totalTypes)
-		}
-
-		total := n.Ca + n.Ce
-		if total > 0 {
-			n.I = n.Ce / total
-		}
-
-		n.D = math.Abs(n.A + n.I - 1)
+		n.computeDistance()
 	}
 }
 
-// ComputeStructuralCoupling calculates SCa and SCe for each node.
-// It finds concrete types that satisfy interfaces in other packages
-// without importing them. Requires packages loaded with NeedTypes.
-func (g *Graph) ComputeStructuralCoupling() {
-	type pkgIfaces struct {
-		id    string
-		ifaces []*types.Interface
-	}
-	type pkgConcretes struct {
-		id        string
-		concretes []types.Type // named types that are not interfaces
+// computeDistance computes A, I and D from the declarations and couplings.
+func (n *Node) computeDistance() {
+	totalTypes := n.Stat.Decls.Type
+	interfaces := n.Stat.Decls.Interface
+	if totalTypes > 0 {
+		n.A = float64(interfaces) / float64(totalTypes)
 	}
 
-	var allIfaces []pkgIfaces
-	var allConcretes []pkgConcretes
-
-	for _, n := range g.Sorted {
-		if n.Package.Types == nil {
-			continue
-		}
-		scope := n.Package.Types.Scope()
-		var ifaces []*types.Interface
-		var concretes []types.Type
-
-		for _, name := range scope.Names() {
-			obj := scope.Lookup(name)
-			tn, ok := obj.(*types.TypeName)
-			if !ok {
-				continue
-			}
-			if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
-				if iface.NumMethods() > 0 {
-					ifaces = append(ifaces, iface)
-				}
-			} else {
-				concretes = append(concretes, tn.Type())
-			}
-		}
-
-		if len(ifaces) > 0 {
-			allIfaces = append(allIfaces, pkgIfaces{id: n.ID, ifaces: ifaces})
-		}
-		if len(concretes) > 0 {
-			allConcretes = append(allConcretes, pkgConcretes{id: n.ID, concretes: concretes})
-		}
+	total := n.Ca + n.Ce
+	if total > 0 {
+		n.I = n.Ce / total
 	}
 
-	// For each pair (concrete pkg A, interface pkg B) where A != B,
-	// check if any concrete in A implements any interface in B.
-	// Only count if A does not import B (for SCe on A)
-	// and B does not import A (for SCa on B).
-	for _, cp := range allConcretes {
-		cn := g.Packages[cp.id]
-		if cn == nil {
-			continue
-		}
-		for _, ip := range allIfaces {
-			if cp.id == ip.id {
-				continue
-			}
-			in := g.Packages[ip.id]
-			if in == nil {
-				continue
-			}
-
-			// Check if any concrete type in cp satisfies any interface in ip.
-			satisfied := false
-			for _, ct := range cp.concretes {
-				for _, iface := range ip.ifaces {
-					if types.Implements(ct, iface) || types.Implements(types.NewPointer(ct), iface) {
-						satisfied = true
-						break
-					}
-				}
-				if satisfied {
-					break
-				}
-			}
-			if !satisfied {
-				continue
-			}
-
-			// cp's types satisfy ip's interfaces.
-			// SCe for cp: only if cp does NOT import ip (otherwise it's already in Ce).
-			if _, imports := cn.Package.Imports[in.PkgPath]; !imports {
-				cn.SCe++
-			}
-			// SCa for ip: only if ip does NOT import cp (otherwise it's already in Ca).
-			if _, imports := in.Package.Imports[cn.PkgPath]; !imports {
-				in.SCa++
-			}
-		}
-	}
+	n.D = math.Abs(n.A + n.I - 1)
 }
 
 func LoadNode(p *packages.Package) *Node {
 	node := &Node{}
 	node.Package = p
 
-	stat, errs := stat.Package(p)
+	stat, imports, errs := stat.Package(p)
 	node.Errors = append(node.Errors, errs...)
 	node.Stat = stat
+	node.ImportSpecs = imports
 
 	return node
 }
 
+// importSpecsOf returns the import specs of path.
+func (n *Node) importSpecsOf(path string) []stat.Import {
+	var specs []stat.Import
+	for _, spec := range n.ImportSpecs {
+		if spec.Path == path {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
 func SortNodes(xs []*Node) {
 	sort.Slice(xs, func(i, k int) bool { return xs[i].ID < xs[k].ID })
 }
@@ -0,0 +1,66 @@
+package pkggraph
+
+import "golang.org/x/tools/go/packages"
+
+// addHiddenEdges adds an edge X -> Z for every X -> [Y] -> Z path,
+// where X and Z are part of the graph, but Y is not.
+//
+// Edge.Hidden is set to the smallest number of hidden packages
+// on such paths. Packages that import each other directly don't
+// get an additional edge.
+func (g *Graph) addHiddenEdges() {
+	// reachable[Y] contains nodes reachable from a hidden package Y
+	// through hidden packages, with the number of hidden packages on the
+	// shortest path, including Y.
+	reachable := map[string]map[*Node]int{}
+
+	var reach func(p *packages.Package) map[*Node]int
+	reach = func(p *packages.Package) map[*Node]int {
+		if r, ok := reachable[p.ID]; ok {
+			return r
+		}
+
+		// Go disallows import cycles, however prevent looping regardless.
+		reachable[p.ID] = nil
+
+		r := map[*Node]int{}
+		for _, imp := range p.Imports {
+			if n, ok := g.Packages[imp.ID]; ok {
+				r[n] = 1
+				continue
+			}
+			for n, hops := range reach(imp) {
+				if prev, ok := r[n]; !ok || hops+1 < prev {
+					r[n] = hops + 1
+				}
+			}
+		}
+		reachable[p.ID] = r
+
+		return r
+	}
+
+	for _, n := range g.Sorted {
+		hidden := map[*Node]int{}
+		for _, imp := range n.Package.Imports {
+			if _, ok := g.Packages[imp.ID]; ok {
+				continue
+			}
+			for dst, hops := range reach(imp) {
+				if prev, ok := hidden[dst]; !ok || hops < prev {
+					hidden[dst] = hops
+				}
+			}
+		}
+
+		for dst, hops := range hidden {
+			if dst == n || n.EdgeTo(dst) != nil {
+				continue
+			}
+			e := n.link(dst)
+			e.Weight = 1
+			e.Hidden = hops
+		}
+		n.sortImports()
+	}
+}
@@ -0,0 +1,30 @@
+package pkggraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHiddenEdges(t *testing.T) {
+	pkgs := testPackages("a->y1", "y1->y2", "y2->c", "a->b", "b->y2", "y1->b")
+	delete(pkgs, "y1")
+	delete(pkgs, "y2")
+
+	g := FromWithOpts(pkgs, FromOpts{HiddenEdges: true})
+
+	var got []string
+	for _, n := range g.Sorted {
+		for _, e := range n.Edges {
+			got = append(got, n.ID+"->"+e.To.ID+"/"+string(rune('0'+e.Hidden)))
+		}
+	}
+
+	want := []string{"a->b/0", "a->c/2", "b->c/1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if plain := From(pkgs); len(edgeList(plain)) != 1 {
+		t.Errorf("expected hidden edges to be disabled by default, got %q", edgeList(plain))
+	}
+}
@@ -0,0 +1,83 @@
+package pkggraph
+
+import "math"
+
+// SystemMetrics are John Lakos's physical design metrics and
+// MacCormack's propagation cost for the whole graph.
+type SystemMetrics struct {
+	// Packages is the number of packages (N).
+	Packages int
+	// CCD is the Cumulative Component Dependency: the sum of DependsOn over all packages.
+	CCD int
+	// ACD is the Average Component Dependency: CCD / N.
+	ACD float64
+	// NCCD is the Normalized CCD: CCD divided by the CCD of a balanced
+	// binary tree with N packages. Values above 1 indicate a more
+	// entangled structure than a tree.
+	NCCD float64
+	// PropagationCost is the fraction of packages affected by a change
+	// in a package, on average: CCD / N².
+	PropagationCost float64
+}
+
+// ComputeSystemMetrics calculates the system level metrics from DependsOn.
+// Stub nodes are ignored.
+func (g *Graph) ComputeSystemMetrics() SystemMetrics {
+	var m SystemMetrics
+	for _, n := range g.Sorted {
+		if n.Stub > 0 {
+			continue
+		}
+		m.Packages++
+		m.CCD += n.DependsOn
+	}
+	if m.Packages == 0 {
+		return m
+	}
+
+	count := float64(m.Packages)
+	m.ACD = float64(m.CCD) / count
+	m.NCCD = float64(m.CCD) / balancedTreeCCD(m.Packages)
+	m.PropagationCost = float64(m.CCD) / (count * count)
+	return m
+}
+
+// balancedTreeCCD returns the CCD of a balanced binary tree with n nodes,
+// (n+1)·log2(n+1) - n.
+func balancedTreeCCD(n int) float64 {
+	count := float64(n)
+	return (count+1)*math.Log2(count+1) - count
+}
+
+// computeDependsOn sets DependsOn using the transitive imports in cache,
+// counting only the packages in the graph.
+func (g *Graph) computeDependsOn(cache map[string][]string) {
+	for _, n := range g.Packages {
+		n.DependsOn = 1
+		for _, id := range cache[n.ID] {
+			if _, ok := g.Packages[id]; ok && id != n.ID {
+				n.DependsOn++
+			}
+		}
+	}
+}
+
+// computeDependsOnFromEdges sets DependsOn by walking the edges,
+// which is used for graphs without underlying packages, e.g. collapsed ones.
+func (g *Graph) computeDependsOnFromEdges() {
+	for _, n := range g.Sorted {
+		seen := map[*Node]bool{n: true}
+		stack := []*Node{n}
+		for len(stack) > 0 {
+			next := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, dep := range next.ImportsNodes {
+				if !seen[dep] {
+					seen[dep] = true
+					stack = append(stack, dep)
+				}
+			}
+		}
+		n.DependsOn = len(seen)
+	}
+}
@@ -0,0 +1,51 @@
+package pkggraph
+
+import (
+	"math"
+	"regexp"
+	"testing"
+)
+
+func TestComputeSystemMetrics(t *testing.T) {
+	g := From(testPackages("a->b", "a->c", "b->d", "c->d"))
+
+	want := map[string]int{"a": 4, "b": 2, "c": 2, "d": 1}
+	for id, dependsOn := range want {
+		if got := g.Packages[id].DependsOn; got != dependsOn {
+			t.Errorf("%s: got DependsOn %d, want %d", id, got, dependsOn)
+		}
+	}
+
+	m := g.ComputeSystemMetrics()
+	if m.Packages != 4 || m.CCD != 9 {
+		t.Errorf("got %d packages and CCD %d, want 4 and 9", m.Packages, m.CCD)
+	}
+	approx := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	approx("ACD", m.ACD, 2.25)
+	approx("NCCD", m.NCCD, 9/(5*math.Log2(5)-4))
+	approx("PropagationCost", m.PropagationCost, 9.0/16)
+}
+
+func TestComputeSystemMetricsEmpty(t *testing.T) {
+	g := From(testPackages())
+	if m := g.ComputeSystemMetrics(); m != (SystemMetrics{}) {
+		t.Errorf("got %+v, want zero metrics", m)
+	}
+}
+
+func TestCollapseDependsOn(t *testing.T) {
+	g := From(testPackages("x/a->y/b", "y/b->z/c", "y/d"))
+	collapsed := g.Collapse(ByRegexp(regexp.MustCompile(`^[^/]+`)))
+
+	want := map[string]int{"x": 3, "y": 2, "z": 1}
+	for id, dependsOn := range want {
+		if got := collapsed.Packages[id].DependsOn; got != dependsOn {
+			t.Errorf("%s: got DependsOn %d, want %d", id, got, dependsOn)
+		}
+	}
+}
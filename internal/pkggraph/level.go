@@ -0,0 +1,127 @@
+package pkggraph
+
+import (
+	"golang.org/x/tools/go/packages"
+)
+
+// ParseLevel parses the granularity of metrics: "package" or
+// a grouping accepted by ParseGroup. It returns nil for "package".
+func ParseLevel(spec string) (GroupFunc, error) {
+	if spec == "" || spec == "package" || spec == "pkg" {
+		return nil, nil
+	}
+	return ParseGroup(spec)
+}
+
+// ComputeGroupMetrics computes Robert Martin's metrics for the group nodes
+// of a graph created by Collapse.
+//
+// Couplings count distinct groups, where only imports crossing group
+// boundaries are considered. Like ComputeMetrics, afferent couplings are
+// counted over allPkgs, unless opts specify a scope. Structural couplings are
+// aggregated from the members, when they have been computed with
+// ComputeStructuralCoupling.
+func (g *Graph) ComputeGroupMetrics(allPkgs map[string]*packages.Package, group GroupFunc, opts MetricsOpts) {
+	groupOf := func(p *packages.Package) string {
+		if key := group(&Node{Package: p}); key != "" {
+			return key
+		}
+		return p.ID
+	}
+
+	byMember := map[*Node]*Node{}
+	for _, n := range g.Sorted {
+		for _, m := range n.Members {
+			byMember[m] = n
+		}
+	}
+
+	// Efferent couplings: groups imported by the members.
+	efferent := map[string]map[string]bool{}
+	for _, n := range g.Sorted {
+		if n.Stub > 0 {
+			continue
+		}
+		imports := map[string]bool{}
+		for _, m := range n.Members {
+			for _, imp := range m.Package.Imports {
+				if !opts.efferent(imp) {
+					continue
+				}
+				if key := groupOf(imp); key != n.ID {
+					imports[key] = true
+				}
+			}
+		}
+		efferent[n.ID] = imports
+		n.Ce = float64(len(imports))
+	}
+
+	// Afferent couplings: groups importing any of the members.
+	afferent := map[string]map[string]bool{}
+	for _, p := range opts.importers(allPkgs) {
+		from := groupOf(p)
+		for _, imp := range p.Imports {
+			to := groupOf(imp)
+			if to == from {
+				continue
+			}
+			if afferent[to] == nil {
+				afferent[to] = map[string]bool{}
+			}
+			afferent[to][from] = true
+		}
+	}
+	for _, n := range g.Sorted {
+		if n.Stub > 0 {
+			continue
+		}
+		n.Ca = float64(len(afferent[n.ID]))
+	}
+
+	// Structural couplings between groups without an import in either direction.
+	structuralEfferent := map[*Node]map[*Node]bool{}
+	structuralAfferent := map[*Node]map[*Node]bool{}
+	for _, n := range g.Sorted {
+		for _, m := range n.Members {
+			for _, target := range m.satisfies {
+				to := byMember[target]
+				if to == nil || to == n {
+					continue
+				}
+				if !efferent[n.ID][to.ID] {
+					if structuralEfferent[n] == nil {
+						structuralEfferent[n] = map[*Node]bool{}
+					}
+					structuralEfferent[n][to] = true
+				}
+				if !efferent[to.ID][n.ID] {
+					if structuralAfferent[to] == nil {
+						structuralAfferent[to] = map[*Node]bool{}
+					}
+					structuralAfferent[to][n] = true
+				}
+			}
+		}
+	}
+
+	for _, n := range g.Sorted {
+		if n.Stub > 0 {
+			continue
+		}
+		n.SCe = float64(len(structuralEfferent[n]))
+		n.SCa = float64(len(structuralAfferent[n]))
+		n.computeDistance()
+
+		typed := false
+		n.typeCount = typeCount{}
+		for _, m := range n.Members {
+			typed = typed || m.Package.Types != nil
+			n.typeCount.Abstract += m.typeCount.Abstract
+			n.typeCount.Total += m.typeCount.Total
+		}
+		if typed {
+			n.computeRefinedDistance()
+		}
+	}
+}
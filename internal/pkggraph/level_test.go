@@ -0,0 +1,34 @@
+package pkggraph
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestComputeGroupMetrics(t *testing.T) {
+	pkgs := testPackages("x/a->x/b", "x/a->y/c", "x/b->y/c", "x/b->y/d", "y/c->y/d", "z/e->x/a", "y/d->fmt")
+
+	group := ByRegexp(regexp.MustCompile(`^[^/]+`))
+	g := From(pkgs).Collapse(group)
+	g.ComputeGroupMetrics(pkgs, group, MetricsOpts{})
+
+	tests := []struct {
+		id     string
+		ca, ce float64
+	}{
+		{"x", 1, 1},
+		{"y", 1, 1},
+		{"z", 0, 1},
+		{"fmt", 1, 0},
+	}
+	for _, test := range tests {
+		n := g.Packages[test.id]
+		if n.Ca != test.ca || n.Ce != test.ce {
+			t.Errorf("%s: got Ca=%v Ce=%v, want Ca=%v Ce=%v", test.id, n.Ca, n.Ce, test.ca, test.ce)
+		}
+	}
+
+	if x := g.Packages["x"]; x.I != 0.5 || x.D != 0.5 {
+		t.Errorf("x: got I=%v D=%v, want I=0.5 D=0.5", x.I, x.D)
+	}
+}
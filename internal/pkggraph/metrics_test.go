@@ -0,0 +1,43 @@
+package pkggraph
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestComputeMetricsWithOpts(t *testing.T) {
+	pkgs := testPackages("example.com/a->example.com/b", "example.com/a->fmt", "example.com/a->example.com/vendor/y", "example.org/c->example.com/b", "example.com/b->fmt")
+
+	tests := []struct {
+		name   string
+		opts   MetricsOpts
+		ca, ce float64
+	}{
+		{"default", MetricsOpts{}, 0, 3},
+		{"excludeStd", MetricsOpts{ExcludeStd: true}, 0, 2},
+		{"excludeVendor", MetricsOpts{ExcludeVendor: true}, 0, 2},
+		{"scope", MetricsOpts{Scope: map[string]*packages.Package{
+			"example.com/a": pkgs["example.com/a"],
+			"example.com/b": pkgs["example.com/b"],
+		}}, 0, 1},
+	}
+	for _, test := range tests {
+		g := From(pkgs)
+		g.ComputeMetricsWithOpts(pkgs, test.opts)
+
+		a := g.Packages["example.com/a"]
+		if a.Ca != test.ca || a.Ce != test.ce {
+			t.Errorf("%s: a got Ca=%v Ce=%v, want Ca=%v Ce=%v", test.name, a.Ca, a.Ce, test.ca, test.ce)
+		}
+	}
+
+	g := From(pkgs)
+	g.ComputeMetricsWithOpts(pkgs, MetricsOpts{Scope: map[string]*packages.Package{
+		"example.com/a": pkgs["example.com/a"],
+		"example.com/b": pkgs["example.com/b"],
+	}})
+	if b := g.Packages["example.com/b"]; b.Ca != 1 || b.Ce != 0 {
+		t.Errorf("scope: b got Ca=%v Ce=%v, want Ca=1 Ce=0", b.Ca, b.Ce)
+	}
+}
@@ -0,0 +1,59 @@
+package pkggraph
+
+import (
+	"sort"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// SDPViolation is an import of a less stable package, which breaks
+// the Stable Dependencies Principle: dependencies should point
+// in the direction of stability.
+type SDPViolation struct {
+	From, To   string
+	FromI, ToI float64
+	// Gap is ToI - FromI.
+	Gap float64
+	// Imports are the import specs in From that create the dependency.
+	Imports []stat.Import `json:",omitempty"`
+}
+
+// BreaksSDP returns whether the edge is an import of a less stable package.
+// It requires the metrics of the nodes to be computed.
+func (e *Edge) BreaksSDP() bool {
+	return e.Weight > 0 && e.Hidden == 0 &&
+		e.From.Stub == 0 && e.To.Stub == 0 &&
+		e.From.I < e.To.I
+}
+
+// SDPViolations returns the imports of less stable packages, sorted by
+// the instability gap, largest first.
+func (g *Graph) SDPViolations() []SDPViolation {
+	var violations []SDPViolation
+	for _, n := range g.Sorted {
+		for _, e := range n.Edges {
+			if !e.BreaksSDP() {
+				continue
+			}
+			violations = append(violations, SDPViolation{
+				From: e.From.ID, To: e.To.ID,
+				FromI: e.From.I, ToI: e.To.I,
+				Gap:     e.To.I - e.From.I,
+				Imports: e.Imports,
+			})
+		}
+	}
+
+	sort.SliceStable(violations, func(i, k int) bool {
+		a, b := &violations[i], &violations[k]
+		switch {
+		case a.Gap != b.Gap:
+			return a.Gap > b.Gap
+		case a.From != b.From:
+			return a.From < b.From
+		default:
+			return a.To < b.To
+		}
+	})
+	return violations
+}
@@ -0,0 +1,34 @@
+package pkggraph
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSDPViolations(t *testing.T) {
+	// I: a=1, c=1, b=1/3, d=2/3, e=1/2, h=2/3, f=i=j=0.
+	pkgs := testPackages("a->b", "c->b", "b->d", "d->e", "d->f", "e->h", "h->i", "h->j")
+	g := From(pkgs)
+	g.ComputeMetrics(pkgs)
+
+	got := g.SDPViolations()
+	want := []SDPViolation{
+		{From: "b", To: "d", FromI: 1.0 / 3, ToI: 2.0 / 3, Gap: 1.0 / 3},
+		{From: "e", To: "h", FromI: 1.0 / 2, ToI: 2.0 / 3, Gap: 1.0 / 6},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].From != want[i].From || got[i].To != want[i].To ||
+			math.Abs(got[i].Gap-want[i].Gap) > 1e-9 ||
+			math.Abs(got[i].FromI-want[i].FromI) > 1e-9 ||
+			math.Abs(got[i].ToI-want[i].ToI) > 1e-9 {
+			t.Errorf("%d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if !g.Packages["b"].Edges[0].BreaksSDP() || g.Packages["a"].Edges[0].BreaksSDP() {
+		t.Error("BreaksSDP does not match the violations")
+	}
+}
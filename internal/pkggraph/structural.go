@@ -0,0 +1,348 @@
+package pkggraph
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Implementation is a concrete type that satisfies an interface
+// declared in another package.
+type Implementation struct {
+	// Type is the concrete type in From.
+	Type *types.TypeName
+	// Interface is the interface in To.
+	Interface *types.TypeName
+	// Pointer is set when only the pointer to Type implements Interface.
+	Pointer bool
+	// TypeSet is set when Interface is a constraint with a type set,
+	// rather than a pure method set.
+	TypeSet bool
+
+	From *Node
+	To   *Node
+}
+
+// Implicit returns whether neither package imports the other,
+// which means the coupling is not visible in the import graph.
+func (impl *Implementation) Implicit() bool {
+	_, fromImportsTo := impl.From.Package.Imports[impl.To.PkgPath]
+	_, toImportsFrom := impl.To.Package.Imports[impl.From.PkgPath]
+	return !fromImportsTo && !toImportsFrom
+}
+
+// TypeName returns the name of the concrete type with its type parameters.
+func (impl *Implementation) TypeName() string {
+	name := impl.Type.Name()
+	if tparams := typeParams(impl.Type); tparams.Len() > 0 {
+		name += "["
+		for i := range tparams.Len() {
+			if i > 0 {
+				name += ", "
+			}
+			name += tparams.At(i).Obj().Name()
+		}
+		name += "]"
+	}
+	return name
+}
+
+// TypeString returns TypeName prefixed with "*" when implemented
+// via pointer receivers.
+func (impl *Implementation) TypeString() string {
+	if impl.Pointer {
+		return "*" + impl.TypeName()
+	}
+	return impl.TypeName()
+}
+
+// StructuralOpts configures FindImplementationsWithOpts.
+type StructuralOpts struct {
+	// Workers is the number of packages evaluated concurrently,
+	// runtime.GOMAXPROCS(0) when zero.
+	Workers int
+	// Progress is called after each package with interfaces is evaluated.
+	Progress func(done, total int)
+	// Constraints includes interfaces with type sets, which are ignored by default.
+	Constraints bool
+}
+
+// ProgressTo returns a StructuralOpts.Progress func that
+// prints the progress to w on a single line.
+func ProgressTo(w io.Writer) func(done, total int) {
+	return func(done, total int) {
+		fmt.Fprintf(w, "\rstructural coupling: %d/%d packages", done, total)
+		if done == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// FindImplementations finds concrete types that satisfy interfaces in other packages.
+// The result is sorted by packages and type names. Requires packages loaded with NeedTypes.
+func (g *Graph) FindImplementations() []Implementation {
+	return g.FindImplementationsWithOpts(StructuralOpts{})
+}
+
+// FindImplementationsWithOpts is FindImplementations with configurable
+// concurrency and progress reporting.
+//
+// Instead of checking every type against every interface, the candidates
+// for an interface are the types that have methods with all of its method
+// names, found via an index from method names to types.
+//
+// Generic types and interfaces match when some instantiation implements
+// the interface, comparing method signatures modulo type parameters.
+func (g *Graph) FindImplementationsWithOpts(opts StructuralOpts) []Implementation {
+	type iface struct {
+		obj     *types.TypeName
+		iface   *types.Interface
+		methods []string
+		typeSet bool
+		tparams *types.TypeParamList
+	}
+	type concrete struct {
+		obj     *types.TypeName
+		node    *Node
+		tparams *types.TypeParamList
+		// methods are the method names of the pointer method set,
+		// which is a superset of the value method set.
+		methods map[string]bool
+	}
+	type pkgIfaces struct {
+		node   *Node
+		ifaces []iface
+	}
+
+	var all []pkgIfaces
+	byMethod := map[string][]*concrete{}
+
+	// Collect the types sequentially, which also computes the lazily
+	// evaluated interface method sets before they are used concurrently.
+	for _, n := range g.Sorted {
+		if n.Package.Types == nil {
+			continue
+		}
+		scope := n.Package.Types.Scope()
+
+		pkg := pkgIfaces{node: n}
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			if it, ok := tn.Type().Underlying().(*types.Interface); ok {
+				if it.NumMethods() == 0 || (!it.IsMethodSet() && !opts.Constraints) {
+					continue
+				}
+				methods := make([]string, it.NumMethods())
+				for i := range methods {
+					methods[i] = it.Method(i).Name()
+				}
+				pkg.ifaces = append(pkg.ifaces, iface{
+					obj:     tn,
+					iface:   it,
+					methods: methods,
+					typeSet: !it.IsMethodSet(),
+					tparams: typeParams(tn),
+				})
+				continue
+			}
+
+			ct := &concrete{obj: tn, node: n, tparams: typeParams(tn), methods: map[string]bool{}}
+			mset := types.NewMethodSet(types.NewPointer(tn.Type()))
+			for i := range mset.Len() {
+				name := mset.At(i).Obj().Name()
+				ct.methods[name] = true
+				byMethod[name] = append(byMethod[name], ct)
+			}
+		}
+		if len(pkg.ifaces) > 0 {
+			all = append(all, pkg)
+		}
+	}
+
+	// candidates returns the types having all methods of it.
+	candidates := func(it *iface) []*concrete {
+		rarest := it.methods[0]
+		for _, name := range it.methods[1:] {
+			if len(byMethod[name]) < len(byMethod[rarest]) {
+				rarest = name
+			}
+		}
+		var result []*concrete
+	next:
+		for _, ct := range byMethod[rarest] {
+			for _, name := range it.methods {
+				if !ct.methods[name] {
+					continue next
+				}
+			}
+			result = append(result, ct)
+		}
+		return result
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([][]Implementation, len(all))
+	work := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				ip := &all[index]
+
+				var impls []Implementation
+				for i := range ip.ifaces {
+					it := &ip.ifaces[i]
+					for _, ct := range candidates(it) {
+						if ct.node == ip.node {
+							continue
+						}
+						impl := Implementation{Type: ct.obj, Interface: it.obj, TypeSet: it.typeSet, From: ct.node, To: ip.node}
+						t := ct.obj.Type()
+						if ct.tparams.Len() > 0 || it.tparams.Len() > 0 {
+							switch {
+							case it.typeSet:
+								// Type sets don't contain uninstantiated types.
+								continue
+							case genericImplements(t, it.iface, ct.tparams, it.tparams):
+							case genericImplements(types.NewPointer(t), it.iface, ct.tparams, it.tparams):
+								impl.Pointer = true
+							default:
+								continue
+							}
+						} else {
+							switch {
+							case types.Implements(t, it.iface):
+							case types.Implements(types.NewPointer(t), it.iface):
+								impl.Pointer = true
+							default:
+								continue
+							}
+						}
+						impls = append(impls, impl)
+					}
+				}
+				results[index] = impls
+
+				if opts.Progress != nil {
+					mu.Lock()
+					done++
+					opts.Progress(done, len(all))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for index := range all {
+		work <- index
+	}
+	close(work)
+	wg.Wait()
+
+	var impls []Implementation
+	for _, result := range results {
+		impls = append(impls, result...)
+	}
+	sortImplementations(impls)
+	return impls
+}
+
+// typeParams returns the type parameters of a generic named type, nil otherwise.
+func typeParams(tn *types.TypeName) *types.TypeParamList {
+	if named, ok := tn.Type().(*types.Named); ok && !tn.IsAlias() {
+		return named.TypeParams()
+	}
+	return nil
+}
+
+func sortImplementations(impls []Implementation) {
+	sort.SliceStable(impls, func(i, k int) bool {
+		a, b := &impls[i], &impls[k]
+		switch {
+		case a.From.ID != b.From.ID:
+			return a.From.ID < b.From.ID
+		case a.To.ID != b.To.ID:
+			return a.To.ID < b.To.ID
+		case a.Type.Name() != b.Type.Name():
+			return a.Type.Name() < b.Type.Name()
+		default:
+			return a.Interface.Name() < b.Interface.Name()
+		}
+	})
+}
+
+// ComputeStructuralCoupling calculates SCa and SCe for each node.
+// It finds concrete types that satisfy interfaces in other packages
+// without importing them. The found implementations are stored in
+// g.Implementations, implementations of interfaces with type sets are
+// not counted. Requires packages loaded with NeedTypes.
+func (g *Graph) ComputeStructuralCoupling() {
+	g.ComputeStructuralCouplingWithOpts(StructuralOpts{})
+}
+
+// ComputeStructuralCouplingWithOpts is ComputeStructuralCoupling with
+// configurable concurrency and progress reporting.
+func (g *Graph) ComputeStructuralCouplingWithOpts(opts StructuralOpts) {
+	impls := g.FindImplementationsWithOpts(opts)
+	g.Implementations = impls
+
+	type pair struct{ from, to *Node }
+	seen := map[pair]bool{}
+
+	for _, impl := range impls {
+		if impl.TypeSet {
+			continue
+		}
+		cn, in := impl.From, impl.To
+		if seen[pair{cn, in}] {
+			continue
+		}
+		seen[pair{cn, in}] = true
+		cn.satisfies = append(cn.satisfies, in)
+
+		// cn's types satisfy in's interfaces.
+		// SCe for cn: only if cn does NOT import in (otherwise it's already in Ce).
+		if _, imports := cn.Package.Imports[in.PkgPath]; !imports {
+			cn.SCe++
+		}
+		// SCa for in: only if in does NOT import cn (otherwise it's already in Ca).
+		if _, imports := in.Package.Imports[cn.PkgPath]; !imports {
+			in.SCa++
+		}
+	}
+}
+
+// AddStructuralEdges adds edges from the package of the concrete type
+// to the package of the interface for implementations without an import
+// between the packages. The edges are not added to ImportsNodes.
+func (g *Graph) AddStructuralEdges(impls []Implementation) {
+	for _, impl := range impls {
+		if !impl.Implicit() {
+			continue
+		}
+		from, to := g.Packages[impl.From.ID], g.Packages[impl.To.ID]
+		if from == nil || to == nil {
+			continue
+		}
+		e := from.link(to)
+		e.Implementations = append(e.Implementations, impl)
+	}
+	for _, n := range g.Sorted {
+		n.sortImports()
+	}
+}
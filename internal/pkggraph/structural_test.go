@@ -0,0 +1,160 @@
+package pkggraph
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/testutil"
+)
+
+// naiveImplementations checks every type against every interface.
+func naiveImplementations(g *Graph) []Implementation {
+	var impls []Implementation
+	for _, cn := range g.Sorted {
+		for _, in := range g.Sorted {
+			if cn == in || cn.Types == nil || in.Types == nil {
+				continue
+			}
+			for _, cname := range cn.Types.Scope().Names() {
+				ct, ok := cn.Types.Scope().Lookup(cname).(*types.TypeName)
+				if !ok || types.IsInterface(ct.Type()) {
+					continue
+				}
+				for _, iname := range in.Types.Scope().Names() {
+					it, ok := in.Types.Scope().Lookup(iname).(*types.TypeName)
+					if !ok || !types.IsInterface(it.Type()) {
+						continue
+					}
+					iface := it.Type().Underlying().(*types.Interface)
+					if iface.NumMethods() == 0 || !iface.IsMethodSet() {
+						continue
+					}
+					impl := Implementation{Type: ct, Interface: it, From: cn, To: in}
+					switch {
+					case types.Implements(ct.Type(), iface):
+					case types.Implements(types.NewPointer(ct.Type()), iface):
+						impl.Pointer = true
+					default:
+						continue
+					}
+					impls = append(impls, impl)
+				}
+			}
+		}
+	}
+	sortImplementations(impls)
+	return impls
+}
+
+func TestFindImplementations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("loads packages")
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedTypes | packages.NeedDeps,
+	}, "io", "bufio", "bytes", "strings", "os", "sort", "fmt", "errors", "container/heap", "hash/crc32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := map[string]*packages.Package{}
+	for _, p := range pkgs {
+		set[p.ID] = p
+	}
+	g := From(set)
+
+	want := naiveImplementations(g)
+	if len(want) == 0 {
+		t.Fatal("expected implementations")
+	}
+
+	done := 0
+	got := g.FindImplementationsWithOpts(StructuralOpts{
+		Workers:  4,
+		Progress: func(n, total int) { done = n },
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %d implementations, want %d", len(got), len(want))
+	}
+	if done == 0 {
+		t.Errorf("progress was not reported")
+	}
+}
+
+func TestFindImplementationsGeneric(t *testing.T) {
+	fset := token.NewFileSet()
+	store := testutil.CheckPackage(t, fset, "store", `package store
+type Store interface {
+	Get(key string) any
+	Put(key string, v any)
+}
+type Reader interface{ Get(key string) any }
+type ReadWriter interface {
+	Reader
+	Put(key string, v any)
+}
+type Getter[T any] interface{ Get(key string) T }
+type Number interface {
+	~int | ~float64
+	String() string
+}
+`)
+	repo := testutil.CheckPackage(t, fset, "repo", `package repo
+type Repo[T any] struct{ items map[string]T }
+func (r *Repo[T]) Get(key string) T { return r.items[key] }
+func (r *Repo[T]) Put(key string, v T) { r.items[key] = v }
+
+type Ints[T ~int] struct{}
+func (Ints[T]) Get(key string) T { return 0 }
+
+type Count int
+func (Count) String() string { return "" }
+`)
+
+	g := From(map[string]*packages.Package{"store": store, "repo": repo})
+
+	list := func(impls []Implementation) []string {
+		var xs []string
+		for _, impl := range impls {
+			x := impl.TypeString() + " " + impl.Interface.Name()
+			if impl.TypeSet {
+				x += " (type set)"
+			}
+			xs = append(xs, x)
+		}
+		return xs
+	}
+
+	got := list(g.FindImplementations())
+	want := []string{
+		"Ints[T] Getter",
+		"*Repo[T] Getter", "*Repo[T] ReadWriter", "*Repo[T] Reader", "*Repo[T] Store",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got = list(g.FindImplementationsWithOpts(StructuralOpts{Constraints: true}))
+	want = append([]string{"Count Number (type set)"}, want...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("with constraints got %q, want %q", got, want)
+	}
+
+	g.ComputeStructuralCouplingWithOpts(StructuralOpts{Constraints: true})
+	if n := g.Packages["repo"]; n.SCe != 1 {
+		t.Errorf("got SCe=%v, want 1", n.SCe)
+	}
+
+	g.AddStructuralEdges(g.FindImplementations())
+	repoNode := g.Packages["repo"]
+	if e := repoNode.EdgeTo(g.Packages["store"]); e == nil || !e.Structural() {
+		t.Errorf("repo->store: got %+v, want structural edge", e)
+	}
+	if len(repoNode.ImportsNodes) != 0 {
+		t.Errorf("structural edges should not be imports, got %v", repoNode.ImportsNodes)
+	}
+}
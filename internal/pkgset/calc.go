@@ -33,6 +33,12 @@ func Parse(_ context.Context, expr []string) (ast.Expr, error) {
 type CalcOpts struct {
 	// TypesMode enables loading type information for structural coupling analysis.
 	TypesMode bool
+	// SyntaxMode enables loading the syntax for cohesion analysis,
+	// it is ignored without TypesMode.
+	SyntaxMode bool
+	// Dir is the directory where packages are loaded from,
+	// empty means the current directory.
+	Dir string
 }
 
 // Calc parses expr and computes the set of packages it describes.
@@ -296,10 +302,12 @@ func CalcWithOpts(parentContext context.Context, expr []string, opts CalcOpts) (
 	}
 
 	return eval(&Context{
-		Context:   parentContext,
-		Env:       Strings(os.Environ()),
-		TypesMode: opts.TypesMode,
-		Variables: map[string]Set{},
+		Context:    parentContext,
+		Env:        Strings(os.Environ()),
+		Dir:        opts.Dir,
+		TypesMode:  opts.TypesMode,
+		SyntaxMode: opts.TypesMode && opts.SyntaxMode,
+		Variables:  map[string]Set{},
 	}, rootExpr)
 }
 
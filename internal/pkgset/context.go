@@ -34,21 +34,29 @@ type Context struct {
 	Context context.Context
 	Tags    Strings
 	Env     Strings
+	Dir     string
 
 	// TypesMode enables loading type information (NeedTypes | NeedDeps).
 	// Required for structural coupling analysis.
 	TypesMode bool
+	// SyntaxMode additionally enables loading the syntax with its type
+	// information (NeedSyntax | NeedTypesInfo), which type checks the
+	// packages and all their dependencies from source.
+	// Required for cohesion analysis.
+	SyntaxMode bool
 
 	Variables map[string]Set
 }
 
 func (ctx Context) Clone() *Context {
 	return &Context{
-		Context:   ctx.Context,
-		Tags:      ctx.Tags.Clone(),
-		Env:       ctx.Env.Clone(),
-		TypesMode: ctx.TypesMode,
-		Variables: ctx.Variables,
+		Context:    ctx.Context,
+		Tags:       ctx.Tags.Clone(),
+		Env:        ctx.Env.Clone(),
+		Dir:        ctx.Dir,
+		TypesMode:  ctx.TypesMode,
+		SyntaxMode: ctx.SyntaxMode,
+		Variables:  ctx.Variables,
 	}
 }
 
@@ -81,10 +89,14 @@ func (ctx Context) Config() *packages.Config {
 	if ctx.TypesMode {
 		mode |= packages.NeedTypes | packages.NeedDeps
 	}
+	if ctx.SyntaxMode {
+		mode |= packages.NeedSyntax | packages.NeedTypesInfo
+	}
 	config := &packages.Config{
 		Context: ctx.Context,
 		Mode:    mode,
 		Env:     ctx.Env,
+		Dir:     ctx.Dir,
 		Tests:   ctx.Tags.ValueOf("test") == "1",
 	}
 
@@ -0,0 +1,320 @@
+// Package predicate implements boolean expressions over struct fields,
+// such as "D > 0.7 && Ca > 5".
+//
+// The expressions use Go syntax and support arithmetic, comparison and
+// logical operators. Identifiers and selectors (e.g. Stat.Go.Lines) refer
+// to fields or niladic methods of the evaluated value.
+package predicate
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed predicate expression.
+type Expr struct {
+	src  string
+	root ast.Expr
+}
+
+// Parse parses src as a predicate expression.
+func Parse(src string) (*Expr, error) {
+	root, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string { return e.src }
+
+// Eval evaluates the expression against v.
+func (e *Expr) Eval(v any) (bool, error) {
+	r, err := eval(e.root, reflect.ValueOf(v))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", e.src, err)
+	}
+	b, ok := r.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: expected a boolean result, got %v", e.src, r)
+	}
+	return b, nil
+}
+
+// Filter returns the elements of xs for which e evaluates to true.
+func Filter[T any](e *Expr, xs []T) ([]T, error) {
+	var result []T
+	for _, x := range xs {
+		ok, err := e.Eval(x)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, x)
+		}
+	}
+	return result, nil
+}
+
+// Names returns the identifiers and selectors used in the expression,
+// in order of appearance.
+func (e *Expr) Names() []string {
+	var names []string
+	seen := map[string]bool{}
+	ast.Inspect(e.root, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			name, ok := selectorName(n.(ast.Expr))
+			if !ok || name == "true" || name == "false" {
+				return true
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+			return false
+		}
+		return true
+	})
+	return names
+}
+
+// Lookup returns the value of the field or niladic method name in v.
+// Nested fields are separated by dots, e.g. "Stat.Go.Lines".
+//
+// Numbers are returned as float64.
+func Lookup(v any, name string) (any, error) {
+	return lookup(reflect.ValueOf(v), name)
+}
+
+func lookup(v reflect.Value, name string) (any, error) {
+	for part := range strings.SplitSeq(name, ".") {
+		next, ok := field(v, part)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		v = next
+	}
+	return convert(v)
+}
+
+func field(v reflect.Value, name string) (reflect.Value, bool) {
+	method := func(v reflect.Value) (reflect.Value, bool) {
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+		if v.Kind() != reflect.Pointer && v.CanAddr() {
+			v = v.Addr()
+		}
+		m := v.MethodByName(name)
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			return reflect.Value{}, false
+		}
+		return m.Call(nil)[0], true
+	}
+
+	s := v
+	for s.Kind() == reflect.Pointer || s.Kind() == reflect.Interface {
+		if s.IsNil() {
+			return reflect.Value{}, false
+		}
+		s = s.Elem()
+	}
+	if s.Kind() == reflect.Struct {
+		if f := s.FieldByName(name); f.IsValid() && f.CanInterface() {
+			return f, true
+		}
+	}
+	if m, ok := method(v); ok {
+		return m, true
+	}
+	return method(s)
+}
+
+func convert(v reflect.Value) (any, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, errors.New("nil value")
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %v", v.Type())
+	}
+}
+
+func selectorName(e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name, true
+	case *ast.SelectorExpr:
+		prefix, ok := selectorName(e.X)
+		if !ok {
+			return "", false
+		}
+		return prefix + "." + e.Sel.Name, true
+	default:
+		return "", false
+	}
+}
+
+func eval(e ast.Expr, v reflect.Value) (any, error) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return eval(e.X, v)
+
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT, token.FLOAT:
+			return strconv.ParseFloat(e.Value, 64)
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		default:
+			return nil, fmt.Errorf("unsupported literal %v", e.Value)
+		}
+
+	case *ast.Ident, *ast.SelectorExpr:
+		name, ok := selectorName(e)
+		if !ok {
+			return nil, fmt.Errorf("unsupported selector")
+		}
+		switch name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return lookup(v, name)
+
+	case *ast.UnaryExpr:
+		x, err := eval(e.X, v)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.NOT:
+			if b, ok := x.(bool); ok {
+				return !b, nil
+			}
+		case token.SUB:
+			if f, ok := x.(float64); ok {
+				return -f, nil
+			}
+		case token.ADD:
+			if f, ok := x.(float64); ok {
+				return f, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid operation %v%v", e.Op, x)
+
+	case *ast.BinaryExpr:
+		x, err := eval(e.X, v)
+		if err != nil {
+			return nil, err
+		}
+
+		// Short-circuit logical operators.
+		if e.Op == token.LAND || e.Op == token.LOR {
+			xb, ok := x.(bool)
+			if !ok {
+				return nil, fmt.Errorf("invalid operation %v %v", x, e.Op)
+			}
+			if (e.Op == token.LAND && !xb) || (e.Op == token.LOR && xb) {
+				return xb, nil
+			}
+			y, err := eval(e.Y, v)
+			if err != nil {
+				return nil, err
+			}
+			yb, ok := y.(bool)
+			if !ok {
+				return nil, fmt.Errorf("invalid operation %v %v %v", x, e.Op, y)
+			}
+			return yb, nil
+		}
+
+		y, err := eval(e.Y, v)
+		if err != nil {
+			return nil, err
+		}
+		return binary(e.Op, x, y)
+
+	default:
+		return nil, fmt.Errorf("unsupported expression %T", e)
+	}
+}
+
+func binary(op token.Token, x, y any) (any, error) {
+	switch x := x.(type) {
+	case float64:
+		y, ok := y.(float64)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			return x / y, nil
+		case token.EQL:
+			return x == y, nil
+		case token.NEQ:
+			return x != y, nil
+		case token.LSS:
+			return x < y, nil
+		case token.LEQ:
+			return x <= y, nil
+		case token.GTR:
+			return x > y, nil
+		case token.GEQ:
+			return x >= y, nil
+		}
+	case string:
+		y, ok := y.(string)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.ADD:
+			return x + y, nil
+		case token.EQL:
+			return x == y, nil
+		case token.NEQ:
+			return x != y, nil
+		}
+	case bool:
+		y, ok := y.(bool)
+		if !ok {
+			break
+		}
+		switch op {
+		case token.EQL:
+			return x == y, nil
+		case token.NEQ:
+			return x != y, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid operation %v %v %v", x, op, y)
+}
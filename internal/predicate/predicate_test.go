@@ -0,0 +1,88 @@
+package predicate
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inner struct {
+	Lines int
+}
+
+type value struct {
+	Name string
+	Ca   float64
+	D    float64
+	Stat inner
+}
+
+func (v *value) Main() bool { return v.Name == "main" }
+
+func TestEval(t *testing.T) {
+	v := &value{Name: "main", Ca: 6, D: 0.75, Stat: inner{Lines: 100}}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"D > 0.7 && Ca > 5", true},
+		{"D > 0.7 && Ca > 6", false},
+		{"D > 0.8 || Ca >= 6", true},
+		{"!(Ca == 6)", false},
+		{"Stat.Lines / 2 == 50", true},
+		{"Ca - 2*3 == 0", true},
+		{`Name == "main" && Main`, true},
+		{"Main == false", false},
+	}
+	for _, test := range tests {
+		expr, err := Parse(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := expr.Eval(v)
+		if err != nil {
+			t.Errorf("%q: %v", test.expr, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: got %v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	for _, invalid := range []string{"Ca", "Unknown > 1", "Name > 1", "D +"} {
+		expr, err := Parse(invalid)
+		if err != nil {
+			continue
+		}
+		if _, err := expr.Eval(v); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	expr, err := Parse("D > 0.7 && (Ca > 5 || Stat.Lines > D) && true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"D", "Ca", "Stat.Lines"}
+	if got := expr.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	values := []*value{{Name: "a", Ca: 1}, {Name: "b", Ca: 6}, {Name: "c", Ca: 8}}
+
+	expr, err := Parse("Ca > 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Filter(expr, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "b" || got[1].Name != "c" {
+		t.Errorf("got %v, want b and c", got)
+	}
+}
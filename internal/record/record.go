@@ -0,0 +1,125 @@
+// Package record defines the machine-readable output schema shared by commands.
+package record
+
+import (
+	"golang.org/x/tools/go/packages"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/pkggraph"
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// Version is the version of the record schema.
+//
+// It must be incremented when fields are removed or their meaning changes.
+// Adding fields doesn't require changing the version, as long as new csv
+// columns are appended at the end.
+const Version = 1
+
+// Package is a single package in the output.
+type Package struct {
+	Version int
+
+	ID      string
+	PkgPath string  `json:",omitempty"`
+	Name    string  `json:",omitempty"`
+	Module  *Module `json:",omitempty"`
+
+	// Parent and Depth are the location of the package in "goda tree".
+	Parent string `json:",omitempty"`
+	Depth  int    `json:",omitempty"`
+	// Elided is set in "goda tree" when the imports of the package are not
+	// expanded below it, because it was printed before or is a std package.
+	Elided bool `json:",omitempty"`
+
+	Imports []string `json:",omitempty"`
+
+	Stat *stat.Stat `json:",omitempty"`
+	Up   *stat.Stat `json:",omitempty"`
+	Down *stat.Stat `json:",omitempty"`
+
+	Metrics *Metrics `json:",omitempty"`
+	Cut     *Cut     `json:",omitempty"`
+
+	Errors []string `json:",omitempty"`
+}
+
+// Module is the module of a package.
+type Module struct {
+	Path    string
+	Version string `json:",omitempty"`
+	Main    bool   `json:",omitempty"`
+}
+
+// Metrics are the package metrics computed by "goda metrics".
+type Metrics struct {
+	Ca float64
+	Ce float64
+	A  float64
+	I  float64
+	D  float64
+
+	SCa float64
+	SCe float64
+
+	RefinedA float64
+	RefinedD float64
+
+	H          float64
+	LCOM       float64
+	Components int
+}
+
+// Cut is the impact of removing a package computed by "goda cut".
+type Cut struct {
+	InDegree  int
+	OutDegree int
+
+	Stat stat.Stat
+}
+
+// FromPackage creates a record with the basic package information.
+func FromPackage(p *packages.Package) *Package {
+	r := &Package{
+		Version: Version,
+		ID:      p.ID,
+		PkgPath: p.PkgPath,
+		Name:    p.Name,
+	}
+	if p.Module != nil {
+		r.Module = &Module{
+			Path:    p.Module.Path,
+			Version: p.Module.Version,
+			Main:    p.Module.Main,
+		}
+	}
+	for _, err := range p.Errors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+	return r
+}
+
+// FromNode creates a record with the package information and stats of n.
+func FromNode(n *pkggraph.Node) *Package {
+	r := FromPackage(n.Package)
+	for _, imp := range n.ImportsNodes {
+		r.Imports = append(r.Imports, imp.ID)
+	}
+	r.Stat = &n.Stat
+	r.Up = &n.Up
+	r.Down = &n.Down
+	for _, err := range n.Errors {
+		r.Errors = append(r.Errors, err.Error())
+	}
+	return r
+}
+
+// MetricsOf returns the metrics of n.
+func MetricsOf(n *pkggraph.Node) *Metrics {
+	return &Metrics{
+		Ca: n.Ca, Ce: n.Ce,
+		A: n.A, I: n.I, D: n.D,
+		SCa: n.SCa, SCe: n.SCe,
+		RefinedA: n.RefinedA, RefinedD: n.RefinedD,
+		H: n.H, LCOM: n.LCOM, Components: n.Components,
+	}
+}
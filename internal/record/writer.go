@@ -0,0 +1,175 @@
+package record
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/stat"
+)
+
+// Writer writes records in a machine-readable format.
+type Writer interface {
+	Write(r *Package) error
+	// Close finishes the output, it doesn't close the underlying writer.
+	Close() error
+}
+
+// IsText returns whether format means the regular templated text output.
+func IsText(format string) bool {
+	return format == "" || strings.EqualFold(format, "text")
+}
+
+// NewWriter creates a writer for the format: json, ndjson or csv.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return &jsonWriter{out: w}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvWriter{out: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected text, json, ndjson or csv", format)
+	}
+}
+
+// jsonWriter writes records as a single JSON array.
+type jsonWriter struct {
+	out   io.Writer
+	count int
+}
+
+func (w *jsonWriter) Write(r *Package) error {
+	data, err := json.MarshalIndent(r, "\t", "\t")
+	if err != nil {
+		return err
+	}
+	sep := ",\n\t"
+	if w.count == 0 {
+		sep = "[\n\t"
+	}
+	w.count++
+	_, err = fmt.Fprintf(w.out, "%s%s", sep, data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	if w.count == 0 {
+		_, err := fmt.Fprintln(w.out, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(w.out, "\n]")
+	return err
+}
+
+// ndjsonWriter writes a record per line.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(r *Package) error { return w.enc.Encode(r) }
+func (w *ndjsonWriter) Close() error           { return nil }
+
+// csvWriter writes records as flattened csv rows.
+type csvWriter struct {
+	out    *csv.Writer
+	header bool
+}
+
+// csvHeader lists the columns of the csv output.
+//
+// New columns are appended at the end, so that the position of the
+// existing columns stays the same within a Version.
+var csvHeader = []string{
+	"version", "id", "pkgpath", "name", "module", "module_version",
+	"parent", "depth", "imports",
+	"go_files", "go_lines", "go_size", "other_files", "other_size",
+	"decls_func", "decls_type", "decls_interface", "decls_const", "decls_var",
+	"up_packages", "down_packages",
+	"ca", "ce", "a", "i", "d", "sca", "sce",
+	"in_degree", "out_degree", "cut_packages", "cut_lines", "cut_size",
+	"errors",
+	"refined_a", "refined_d", "h", "lcom", "components",
+	"elided",
+}
+
+func (w *csvWriter) Write(r *Package) error {
+	if !w.header {
+		w.header = true
+		if err := w.out.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	return w.out.Write(csvRow(r))
+}
+
+func (w *csvWriter) Close() error {
+	if !w.header {
+		if err := w.out.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	w.out.Flush()
+	return w.out.Error()
+}
+
+func csvRow(r *Package) []string {
+	itoa := func(v int64) string { return strconv.FormatInt(v, 10) }
+	ftoa := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+	row := []string{
+		strconv.Itoa(r.Version), r.ID, r.PkgPath, r.Name, "", "",
+		r.Parent, strconv.Itoa(r.Depth), strings.Join(r.Imports, " "),
+	}
+	if r.Module != nil {
+		row[4], row[5] = r.Module.Path, r.Module.Version
+	}
+
+	if s := r.Stat; s != nil {
+		row = append(row,
+			strconv.Itoa(s.Go.Files), strconv.Itoa(s.Go.Lines), itoa(int64(s.Go.Size)),
+			strconv.Itoa(s.OtherFiles.Files), itoa(int64(s.OtherFiles.Size)),
+			itoa(s.Decls.Func), itoa(s.Decls.Type), itoa(s.Decls.Interface), itoa(s.Decls.Const), itoa(s.Decls.Var),
+		)
+	} else {
+		row = append(row, make([]string, 10)...)
+	}
+
+	packageCount := func(s *stat.Stat) string {
+		if s == nil {
+			return ""
+		}
+		return itoa(s.PackageCount)
+	}
+	row = append(row, packageCount(r.Up), packageCount(r.Down))
+
+	if m := r.Metrics; m != nil {
+		row = append(row, ftoa(m.Ca), ftoa(m.Ce), ftoa(m.A), ftoa(m.I), ftoa(m.D), ftoa(m.SCa), ftoa(m.SCe))
+	} else {
+		row = append(row, make([]string, 7)...)
+	}
+
+	if c := r.Cut; c != nil {
+		row = append(row,
+			strconv.Itoa(c.InDegree), strconv.Itoa(c.OutDegree),
+			itoa(c.Stat.PackageCount), strconv.Itoa(c.Stat.Go.Lines), itoa(int64(c.Stat.AllFiles().Size)),
+		)
+	} else {
+		row = append(row, make([]string, 5)...)
+	}
+
+	row = append(row, strings.Join(r.Errors, "; "))
+
+	if m := r.Metrics; m != nil {
+		row = append(row, ftoa(m.RefinedA), ftoa(m.RefinedD), ftoa(m.H), ftoa(m.LCOM), strconv.Itoa(m.Components))
+	} else {
+		row = append(row, make([]string, 5)...)
+	}
+
+	row = append(row, strconv.FormatBool(r.Elided))
+	return row
+}
@@ -0,0 +1,248 @@
+// Package sarif writes findings in the Static Analysis Results
+// Interchange Format (SARIF) 2.1.0, as ingested by code scanning tools.
+package sarif
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/goda/internal/git"
+)
+
+const (
+	// Version is the SARIF version of the log.
+	Version = "2.1.0"
+	// Schema is the JSON schema of the log.
+	Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// srcRoot is the base of the relative artifact locations.
+	srcRoot = "%SRCROOT%"
+)
+
+// Level is the severity of a result.
+type Level string
+
+const (
+	Error   Level = "error"
+	Warning Level = "warning"
+	Note    Level = "note"
+)
+
+// Rule describes a kind of result.
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// Result is a finding at a line of a file.
+type Result struct {
+	RuleID  string
+	Level   Level
+	Message string
+
+	// File is the path of the file, the result has no location when empty.
+	File string
+	// Line is the line in File, the whole file when zero.
+	Line int
+
+	// Suppressed marks results that are exempt, with an optional justification.
+	Suppressed    bool
+	Justification string
+}
+
+// Log is the SARIF log of a single run of goda.
+type Log struct {
+	// Root is the directory relative file locations refer to,
+	// usually the root of the repository.
+	Root    string
+	Rules   []Rule
+	Results []Result
+}
+
+// Root returns the root of the git repository containing the working
+// directory, or the working directory outside of a repository.
+func Root(ctx context.Context) string {
+	if root, err := git.Toplevel(ctx, "."); err == nil {
+		return root
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return wd
+}
+
+// AddRule adds rule, unless a rule with the same ID exists.
+func (log *Log) AddRule(rule Rule) {
+	for _, r := range log.Rules {
+		if r.ID == rule.ID {
+			return
+		}
+	}
+	log.Rules = append(log.Rules, rule)
+}
+
+// Add adds results.
+func (log *Log) Add(results ...Result) {
+	log.Results = append(log.Results, results...)
+}
+
+// WriteFile writes the log to filename.
+func (log *Log) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := log.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the log as SARIF JSON.
+func (log *Log) Write(w io.Writer) error {
+	ruleIndex := map[string]int{}
+	rules := []rule{}
+	for i, r := range log.Rules {
+		ruleIndex[r.ID] = i
+		rules = append(rules, rule{ID: r.ID, ShortDescription: message{Text: r.Description}})
+	}
+
+	results := []result{}
+	for _, r := range log.Results {
+		index, ok := ruleIndex[r.RuleID]
+		if !ok {
+			index = len(rules)
+			ruleIndex[r.RuleID] = index
+			rules = append(rules, rule{ID: r.RuleID, ShortDescription: message{Text: r.RuleID}})
+		}
+
+		res := result{
+			RuleID:    r.RuleID,
+			RuleIndex: index,
+			Level:     r.Level,
+			Message:   message{Text: r.Message},
+		}
+		if res.Level == "" {
+			res.Level = Warning
+		}
+		if r.File != "" {
+			loc := physicalLocation{ArtifactLocation: log.artifact(r.File)}
+			if r.Line > 0 {
+				loc.Region = &region{StartLine: r.Line}
+			}
+			res.Locations = []location{{PhysicalLocation: loc}}
+		}
+		if r.Suppressed {
+			res.Suppressions = []suppression{{Kind: "external", Justification: r.Justification}}
+		}
+		results = append(results, res)
+	}
+
+	out := run{
+		Tool: tool{Driver: driver{
+			Name:           "goda",
+			InformationURI: "https://github.com/flamingoosesoftwareinc/goda",
+			Rules:          rules,
+		}},
+		Results: results,
+	}
+	if log.Root != "" {
+		out.OriginalURIBaseIDs = map[string]artifactLocation{
+			srcRoot: {URI: fileURI(log.Root) + "/"},
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{
+		Version: Version,
+		Schema:  Schema,
+		Runs:    []run{out},
+	})
+}
+
+// The JSON representation of the subset of SARIF used by Log.
+type (
+	sarifLog struct {
+		Version string `json:"version"`
+		Schema  string `json:"$schema"`
+		Runs    []run  `json:"runs"`
+	}
+	run struct {
+		Tool               tool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]artifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []result                    `json:"results"`
+	}
+	tool struct {
+		Driver driver `json:"driver"`
+	}
+	driver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+		Rules          []rule `json:"rules"`
+	}
+	rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	result struct {
+		RuleID       string        `json:"ruleId"`
+		RuleIndex    int           `json:"ruleIndex"`
+		Level        Level         `json:"level"`
+		Message      message       `json:"message"`
+		Locations    []location    `json:"locations,omitempty"`
+		Suppressions []suppression `json:"suppressions,omitempty"`
+	}
+	message struct {
+		Text string `json:"text"`
+	}
+	location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	physicalLocation struct {
+		ArtifactLocation artifactLocation `json:"artifactLocation"`
+		Region           *region          `json:"region,omitempty"`
+	}
+	artifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+	region struct {
+		StartLine int `json:"startLine"`
+	}
+	suppression struct {
+		Kind          string `json:"kind"`
+		Justification string `json:"justification,omitempty"`
+	}
+)
+
+// artifact returns the location of file relative to the root when inside it.
+func (log *Log) artifact(file string) artifactLocation {
+	if log.Root != "" && filepath.IsAbs(file) {
+		rel, err := filepath.Rel(log.Root, file)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return artifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).EscapedPath(), URIBaseID: srcRoot}
+		}
+	}
+	if filepath.IsAbs(file) {
+		return artifactLocation{URI: fileURI(file)}
+	}
+	return artifactLocation{URI: (&url.URL{Path: filepath.ToSlash(file)}).EscapedPath()}
+}
+
+// fileURI returns the file URI of an absolute path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,83 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	log := &Log{Root: "/repo"}
+	log.AddRule(Rule{ID: "deny", Description: "Forbidden import."})
+	log.AddRule(Rule{ID: "deny", Description: "Duplicate."})
+	log.Add(
+		Result{RuleID: "deny", Level: Error, Message: "a imports b", File: "/repo/a/a.go", Line: 3},
+		Result{RuleID: "fail-if", Message: "c: D > 0.7", File: "/elsewhere/c.go", Suppressed: true, Justification: "legacy"},
+	)
+
+	var buf bytes.Buffer
+	if err := log.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			OriginalURIBaseIDs map[string]struct{ URI string } `json:"originalUriBaseIds"`
+			Results            []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string
+							URIBaseID string `json:"uriBaseId"`
+						}
+						Region *struct{ StartLine int }
+					}
+				}
+				Suppressions []struct{ Kind, Justification string }
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("got %s", buf.String())
+	}
+	run := got.Runs[0]
+	var ruleIDs []string
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+	}
+	if !reflect.DeepEqual(ruleIDs, []string{"deny", "fail-if"}) {
+		t.Errorf("got rules %v", ruleIDs)
+	}
+	if run.OriginalURIBaseIDs["%SRCROOT%"].URI != "file:///repo/" {
+		t.Errorf("got base %v", run.OriginalURIBaseIDs)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results", len(run.Results))
+	}
+	deny, failIf := run.Results[0], run.Results[1]
+	if loc := deny.Locations[0].PhysicalLocation; deny.Level != "error" ||
+		loc.ArtifactLocation.URI != "a/a.go" || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" ||
+		loc.Region == nil || loc.Region.StartLine != 3 {
+		t.Errorf("got deny result %+v", deny)
+	}
+	if loc := failIf.Locations[0].PhysicalLocation; failIf.Level != "warning" || failIf.RuleIndex != 1 ||
+		loc.ArtifactLocation.URI != "file:///elsewhere/c.go" || loc.Region != nil ||
+		len(failIf.Suppressions) != 1 || failIf.Suppressions[0].Justification != "legacy" {
+		t.Errorf("got fail-if result %+v", failIf)
+	}
+}
@@ -0,0 +1,149 @@
+package stat
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// API stats about exported top-level declarations.
+type API struct {
+	Func   int64
+	Method int64 // methods of exported types
+	Type   int64
+	Const  int64
+	Var    int64
+
+	// Undocumented is the count of exported declarations without a doc comment.
+	Undocumented int64
+	// Deprecated is the count of exported declarations with a "Deprecated:" paragraph.
+	Deprecated int64
+}
+
+func (s *API) Add(b API) {
+	s.Func += b.Func
+	s.Method += b.Method
+	s.Type += b.Type
+	s.Const += b.Const
+	s.Var += b.Var
+	s.Undocumented += b.Undocumented
+	s.Deprecated += b.Deprecated
+}
+
+func (s *API) Sub(b API) {
+	s.Func -= b.Func
+	s.Method -= b.Method
+	s.Type -= b.Type
+	s.Const -= b.Const
+	s.Var -= b.Var
+	s.Undocumented -= b.Undocumented
+	s.Deprecated -= b.Deprecated
+}
+
+// Total returns the count of exported declarations.
+func (s *API) Total() int64 {
+	return s.Func + s.Method + s.Type + s.Const + s.Var
+}
+
+// Documented returns the percentage of exported declarations with a doc comment.
+func (s *API) Documented() float64 {
+	total := s.Total()
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(total-s.Undocumented) / float64(total)
+}
+
+// APIFromAst counts the exported declarations in f.
+//
+// The doc comment of a parenthesized declaration group documents the specs
+// in it that don't have their own.
+func APIFromAst(f *ast.File) API {
+	stat := API{}
+	count := func(counter *int64, docs ...*ast.CommentGroup) {
+		*counter++
+		var doc *ast.CommentGroup
+		for _, d := range docs {
+			if d != nil {
+				doc = d
+				break
+			}
+		}
+		switch {
+		case doc == nil:
+			stat.Undocumented++
+		case deprecated(doc):
+			stat.Deprecated++
+		}
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						count(&stat.Type, spec.Doc, decl.Doc)
+					}
+				case *ast.ValueSpec:
+					counter := &stat.Var
+					if decl.Tok == token.CONST {
+						counter = &stat.Const
+					}
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							count(counter, spec.Doc, decl.Doc)
+						}
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			if decl.Recv == nil {
+				count(&stat.Func, decl.Doc)
+				continue
+			}
+			if recv := receiverName(decl.Recv); recv != nil && recv.IsExported() {
+				count(&stat.Method, decl.Doc)
+			}
+		}
+	}
+	return stat
+}
+
+// receiverName returns the name of the receiver base type.
+func receiverName(recv *ast.FieldList) *ast.Ident {
+	if len(recv.List) == 0 {
+		return nil
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// deprecated returns whether doc contains a paragraph starting with "Deprecated: ".
+func deprecated(doc *ast.CommentGroup) bool {
+	for paragraph := range strings.SplitSeq(doc.Text(), "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated: ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package stat
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestAPIFromAst(t *testing.T) {
+	const src = `package a
+
+// Func is documented.
+func Func() {}
+
+func Undocumented() {}
+
+func unexported() {}
+
+// T is a type.
+//
+// Deprecated: use U.
+type T struct{}
+
+// Method is documented.
+func (*T) Method() {}
+
+func (T) Undocumented() {}
+
+type t struct{}
+
+func (t) Method() {}
+
+// Values are documented by the group.
+const (
+	A, b = 1, 2
+	// C is documented.
+	C = 3
+)
+
+var X, Y int
+
+type G[E any] struct{}
+
+// Deprecated: old.
+func (G[E]) Method() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := APIFromAst(f)
+	want := API{
+		Func:         2,
+		Method:       3,
+		Type:         2,
+		Const:        2,
+		Var:          2,
+		Undocumented: 5,
+		Deprecated:   2,
+	}
+	if got != want {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if got.Total() != 11 {
+		t.Errorf("got total %d, want 11", got.Total())
+	}
+}
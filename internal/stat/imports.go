@@ -0,0 +1,151 @@
+package stat
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Import describes a single import spec in a Go source file.
+type Import struct {
+	// Path is the imported package path.
+	Path string
+	// Name is the explicit package name: "_", "." or an alias.
+	// It is empty when the import is not renamed.
+	Name string
+
+	// File and Line specify the position of the import spec.
+	File string
+	Line int
+
+	// Test is true when the import is in a _test.go file,
+	// which are only loaded for packages with tests.
+	Test bool
+	// Constraint is the build constraint of the file from its //go:build
+	// line and its GOOS and GOARCH file name suffixes, if any.
+	Constraint string
+}
+
+// Blank returns whether the package is imported only for side effects.
+func (imp Import) Blank() bool { return imp.Name == "_" }
+
+// Dot returns whether the package is dot imported.
+func (imp Import) Dot() bool { return imp.Name == "." }
+
+// Aliased returns whether the package is imported with a different name.
+func (imp Import) Aliased() bool { return imp.Name != "" && !imp.Blank() && !imp.Dot() }
+
+// ImportsFromAst returns the import specs in f.
+func ImportsFromAst(fset *token.FileSet, f *ast.File) []Import {
+	filename := fset.Position(f.Package).Filename
+	test := strings.HasSuffix(filename, "_test.go")
+	buildConstraint := BuildConstraint(fset, f)
+
+	var imports []Import
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		imp := Import{
+			Path:       path,
+			File:       filename,
+			Line:       fset.Position(spec.Pos()).Line,
+			Test:       test,
+			Constraint: buildConstraint,
+		}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+	return imports
+}
+
+// BuildConstraint returns the build constraint of f, combining the
+// //go:build expression with the GOOS and GOARCH suffixes of the file name.
+func BuildConstraint(fset *token.FileSet, f *ast.File) string {
+	expr := goBuild(f)
+	if suffix := fileNameConstraint(fset.Position(f.Package).Filename); suffix != nil {
+		if expr == nil {
+			expr = suffix
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: suffix}
+		}
+	}
+
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
+
+// goBuild returns the //go:build expression of f.
+func goBuild(f *ast.File) constraint.Expr {
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			return expr
+		}
+	}
+	return nil
+}
+
+// fileNameConstraint returns the constraint implied by the file name,
+// as in name_GOOS.go, name_GOARCH.go or name_GOOS_GOARCH.go.
+func fileNameConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	// The part before the first underscore is never a constraint.
+	_, name, ok := strings.Cut(name, "_")
+	if !ok {
+		return nil
+	}
+
+	parts := strings.Split(name, "_")
+	n := len(parts)
+	switch {
+	case n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]]:
+		return &constraint.AndExpr{
+			X: &constraint.TagExpr{Tag: parts[n-2]},
+			Y: &constraint.TagExpr{Tag: parts[n-1]},
+		}
+	case knownOS[parts[n-1]] || knownArch[parts[n-1]]:
+		return &constraint.TagExpr{Tag: parts[n-1]}
+	default:
+		return nil
+	}
+}
+
+// knownOS and knownArch are the GOOS and GOARCH values
+// recognized in file names, as listed in go/build.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"js": true, "linux": true, "nacl": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true,
+		"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+		"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+		"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
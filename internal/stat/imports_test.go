@@ -0,0 +1,109 @@
+package stat
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestImportsFromAst(t *testing.T) {
+	const src = `//go:build linux && !386
+
+package a
+
+import (
+	"fmt"
+	_ "embed"
+	. "strings"
+	str "strconv"
+)
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a_test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ImportsFromAst(fset, f)
+	want := []Import{
+		{Path: "fmt", File: "a_test.go", Line: 6, Test: true, Constraint: "linux && !386"},
+		{Path: "embed", Name: "_", File: "a_test.go", Line: 7, Test: true, Constraint: "linux && !386"},
+		{Path: "strings", Name: ".", File: "a_test.go", Line: 8, Test: true, Constraint: "linux && !386"},
+		{Path: "strconv", Name: "str", File: "a_test.go", Line: 9, Test: true, Constraint: "linux && !386"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if !got[1].Blank() || !got[2].Dot() || !got[3].Aliased() || got[0].Aliased() {
+		t.Errorf("invalid import kinds: %+v", got)
+	}
+}
+
+func TestBuildConstraint(t *testing.T) {
+	tests := []struct {
+		filename, src, want string
+	}{
+		{"a.go", "package a", ""},
+		{"linux.go", "package a", ""},
+		{"a_linux.go", "package a", "linux"},
+		{"a_linux_test.go", "package a", "linux"},
+		{"a_windows_amd64.go", "package a", "windows && amd64"},
+		{"a_arm64.go", "package a", "arm64"},
+		{"a_unix.go", "package a", ""},
+		{"a.go", "//go:build linux || darwin\n\npackage a", "linux || darwin"},
+		{"a_amd64.go", "//go:build linux || darwin\n\npackage a", "(linux || darwin) && amd64"},
+	}
+	for _, tt := range tests {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, tt.filename, tt.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := BuildConstraint(fset, f); got != tt.want {
+			t.Errorf("%s %q: got %q, want %q", tt.filename, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestPackageIgnoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	p := &packages.Package{
+		Name:    "a",
+		GoFiles: []string{write("a.go", "package a\n\nimport \"fmt\"\n")},
+		IgnoredFiles: []string{
+			write("a_windows.go", "package a\n\nimport \"syscall\"\n"),
+			write("gen.go", "//go:build ignore\n\npackage main\n\nimport \"os\"\n"),
+			write("a.s", "TEXT ·f(SB),0,$0\n"),
+		},
+	}
+
+	info, imports, errs := Package(p)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if info.Go.Files != 1 {
+		t.Errorf("got %d Go files, want only the built file", info.Go.Files)
+	}
+
+	var got []string
+	for _, imp := range imports {
+		got = append(got, imp.Path+" "+imp.Constraint)
+	}
+	want := []string{"fmt ", "syscall windows", "os ignore"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
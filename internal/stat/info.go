@@ -6,6 +6,7 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -18,6 +19,7 @@ type Stat struct {
 
 	Decls  Decls
 	Tokens Tokens
+	API    API
 }
 
 func (info *Stat) AllFiles() Source {
@@ -33,6 +35,7 @@ func (s *Stat) Add(b Stat) {
 	s.OtherFiles.Add(b.OtherFiles)
 	s.Decls.Add(b.Decls)
 	s.Tokens.Add(b.Tokens)
+	s.API.Add(b.API)
 }
 
 func (s *Stat) Sub(b Stat) {
@@ -41,10 +44,15 @@ func (s *Stat) Sub(b Stat) {
 	s.OtherFiles.Sub(b.OtherFiles)
 	s.Decls.Sub(b.Decls)
 	s.Tokens.Sub(b.Tokens)
+	s.API.Sub(b.API)
 }
 
-func Package(p *packages.Package) (Stat, []error) {
+// Package calculates stats for p and collects the import specs of its Go files,
+// including the files excluded by build constraints, which don't count
+// towards the stats.
+func Package(p *packages.Package) (Stat, []Import, []error) {
 	var info Stat
+	var imports []Import
 	var errs []error
 
 	info.PackageCount = 1
@@ -69,6 +77,22 @@ func Package(p *packages.Package) (Stat, []error) {
 
 		info.Decls.Add(DeclsFromAst(f))
 		info.Tokens.Add(TokensFromAst(f))
+		// Exported identifiers of commands are not importable.
+		if p.Name != "main" {
+			info.API.Add(APIFromAst(f))
+		}
+		imports = append(imports, ImportsFromAst(fset, f)...)
+	}
+
+	for _, filename := range p.IgnoredFiles {
+		if !strings.HasSuffix(filename, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		imports = append(imports, ImportsFromAst(fset, f)...)
 	}
 
 	for _, filename := range p.OtherFiles {
@@ -82,5 +106,5 @@ func Package(p *packages.Package) (Stat, []error) {
 		}
 	}
 
-	return info, errs
+	return info, imports, errs
 }
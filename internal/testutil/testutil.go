@@ -0,0 +1,34 @@
+// Package testutil contains helpers shared by the tests of several packages.
+package testutil
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CheckPackage type checks a single file package, as loaded with
+// NeedTypes, NeedSyntax and NeedTypesInfo.
+func CheckPackage(t testing.TB, fset *token.FileSet, path, src string) *packages.Package {
+	t.Helper()
+	f, err := parser.ParseFile(fset, path+".go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{}}
+	pkg, err := new(types.Config).Check(path, fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &packages.Package{
+		ID: path, PkgPath: path, Name: pkg.Name(),
+		Types:     pkg,
+		TypesInfo: info,
+		Syntax:    []*ast.File{f},
+		Imports:   map[string]*packages.Package{},
+	}
+}
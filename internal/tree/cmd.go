@@ -12,12 +12,14 @@ import (
 	"golang.org/x/tools/go/packages"
 
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
+	"github.com/flamingoosesoftwareinc/goda/internal/record"
 	"github.com/flamingoosesoftwareinc/goda/internal/templates"
 )
 
 type Command struct {
 	printStandard bool
 	format        string
+	output        string
 }
 
 func (*Command) Name() string     { return "tree" }
@@ -25,12 +27,17 @@ func (*Command) Synopsis() string { return "Print dependency tree." }
 func (*Command) Usage() string {
 	return `tree <expr>:
 	Print dependency tree of packages.
+
+	Packages that were already printed and std packages are marked
+	with ~ and their imports are not repeated. With -o json, ndjson
+	or csv such packages have Elided set.
 `
 }
 
 func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 	f.StringVar(&cmd.format, "f", "{{.ID}}", "formatting")
+	f.StringVar(&cmd.output, "o", "text", "output format (text, json, ndjson, csv)")
 }
 
 func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
@@ -40,6 +47,15 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
+	var records record.Writer
+	if !record.IsText(cmd.output) {
+		records, err = record.NewWriter(cmd.output, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitUsageError
+		}
+	}
+
 	if !cmd.printStandard {
 		go pkgset.LoadStd()
 	}
@@ -54,10 +70,37 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 	roots := pkgset.Sources(result)
 
 	printed := map[string]bool{}
+	var writeErr error
 
 	var visit func(int, string, *packages.Package, bool)
 	visit = func(ident int, parentID string, p *packages.Package, last bool) {
-		if last {
+		if writeErr != nil {
+			return
+		}
+
+		keys := []string{}
+		for id := range p.Imports {
+			if _, ok := result[id]; !ok {
+				continue
+			}
+			keys = append(keys, id)
+		}
+		sort.Strings(keys)
+		elided := printed[p.ID] || pkgset.IsStd(p)
+
+		if records != nil {
+			r := record.FromPackage(p)
+			if parentID != "\x00" {
+				r.Parent = parentID
+			}
+			r.Depth = ident
+			r.Imports = keys
+			r.Elided = elided
+			if err := records.Write(r); err != nil {
+				writeErr = err
+				return
+			}
+		} else if last {
 			fmt.Fprint(os.Stdout, strings.Repeat("  ", ident), "  └ ")
 		} else {
 			fmt.Fprint(os.Stdout, strings.Repeat("  ", ident), "  ├ ")
@@ -67,30 +110,27 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 			ParentID string
 			*packages.Package
 		}
-		err := t.Execute(os.Stdout, packageWithImporter{
-			ParentID: parentID,
-			Package:  p,
-		})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "template error: %v\n", err)
+		if records == nil {
+			err := t.Execute(os.Stdout, packageWithImporter{
+				ParentID: parentID,
+				Package:  p,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "template error: %v\n", err)
+			}
 		}
 
-		if printed[p.ID] || pkgset.IsStd(p) {
-			fmt.Fprintln(os.Stdout, " ~")
+		if elided {
+			if records == nil {
+				fmt.Fprintln(os.Stdout, " ~")
+			}
 			return
 		}
-		fmt.Fprintln(os.Stdout)
-
-		printed[p.ID] = true
-		keys := []string{}
-		for id := range p.Imports {
-			if _, ok := result[id]; !ok {
-				continue
-			}
-			keys = append(keys, id)
+		if records == nil {
+			fmt.Fprintln(os.Stdout)
 		}
 
-		sort.Strings(keys)
+		printed[p.ID] = true
 		for i, id := range keys {
 			dep := p.Imports[id]
 			visit(ident+1, p.ID, dep, i == len(keys)-1)
@@ -101,5 +141,16 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		visit(0, "\x00", root, false)
 	}
 
+	if writeErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to output: %v\n", writeErr)
+		return subcommands.ExitFailure
+	}
+	if records != nil {
+		if err := records.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to output: %v\n", err)
+			return subcommands.ExitFailure
+		}
+	}
+
 	return subcommands.ExitSuccess
 }
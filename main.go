@@ -10,9 +10,14 @@ import (
 
 	"github.com/google/subcommands"
 
+	"github.com/flamingoosesoftwareinc/goda/internal/api"
+	"github.com/flamingoosesoftwareinc/goda/internal/check"
+	"github.com/flamingoosesoftwareinc/goda/internal/cochange"
 	"github.com/flamingoosesoftwareinc/goda/internal/cut"
 	"github.com/flamingoosesoftwareinc/goda/internal/exec"
 	"github.com/flamingoosesoftwareinc/goda/internal/graph"
+	"github.com/flamingoosesoftwareinc/goda/internal/hotspots"
+	"github.com/flamingoosesoftwareinc/goda/internal/implements"
 	"github.com/flamingoosesoftwareinc/goda/internal/list"
 	"github.com/flamingoosesoftwareinc/goda/internal/metrics"
 	"github.com/flamingoosesoftwareinc/goda/internal/pkgset"
@@ -33,8 +38,14 @@ func main() {
 	cmds.Register(&weight.Command{}, "")
 	cmds.Register(&weightdiff.Command{}, "")
 	cmds.Register(&graph.Command{}, "")
+	cmds.Register(&graph.DiffCommand{}, "")
 	cmds.Register(&cut.Command{}, "")
 	cmds.Register(&metrics.Command{}, "")
+	cmds.Register(&implements.Command{}, "")
+	cmds.Register(&cochange.Command{}, "")
+	cmds.Register(&hotspots.Command{}, "")
+	cmds.Register(&api.Command{}, "")
+	cmds.Register(&check.Command{}, "")
 	cmds.Register(&ExprHelp{}, "")
 	cmds.Register(&FormatHelp{}, "")
 
@@ -192,12 +203,46 @@ and upstream statistics:
         *Package
 
         ImportsNodes []*Node
+        Edges        []*Edge // Edges to ImportsNodes, -structural and -cochange edges.
+        Members      []*Node // Nodes merged by "graph -collapse".
 
         Stat Stat // Stats about the current node.
         Up   Stat // Stats about upstream nodes.
         Down Stat // Stats about downstream nodes.
     }
 
+    type Edge struct {
+        From, To *Node
+        Weight   int // Number of package imports the edge represents.
+        Hidden   int // Excluded packages between From and To (with -hidden).
+
+        Imports []Import // Import specs in From that create this edge.
+
+        // Types in From satisfying interfaces in To (with -structural).
+        Implementations []Implementation
+
+        // Commits changing both From and To (with -cochange).
+        CoChanges int
+    }
+
+Edges additionally have methods describing the import specs: Files,
+TestOnly, Blank, Dot, Aliased, Conditional and Constraints. Structural
+returns whether the edge comes only from -structural and CoChangeOnly
+whether it comes only from -cochange.
+
+    type Import struct {
+        Path       string
+        Name       string // "_", "." or an alias, empty when not renamed.
+        File       string
+        Line       int
+        Test       bool   // Whether the import is in a _test.go file.
+        Constraint string // The //go:build expression and GOOS/GOARCH suffix of the file.
+    }
+
+As an example, to list test-only dependencies of packages:
+
+    goda list -f "{{.ID}}{{range .Edges}}{{if .TestOnly}} {{.To.ID}}{{end}}{{end}}" "test=1(./...)"
+
     type Package struct {
         ID      string // ID is a unique identifier for a package,
         PkgPath string // PkgPath is the full import path of the package.
@@ -227,6 +272,7 @@ Statistics for package contains the following information:
 
         Decls  Decls
         Tokens Tokens
+        API    API
     }
 
 The source information contains the following information:
@@ -262,6 +308,26 @@ binary size.
         Basic   int64
     }
 
+The exported declarations, as summarized by "goda api", are counted
+separately; main packages have none:
+
+    type API struct {
+        Func         int64
+        Method       int64 // methods of exported types
+        Type         int64
+        Const        int64
+        Var          int64
+        Undocumented int64 // declarations without a doc comment
+        Deprecated   int64 // declarations with a "Deprecated:" paragraph
+    }
+
+    func (*API) Total() int64        // count of exported declarations
+    func (*API) Documented() float64 // percentage with a doc comment
+
+For example, to print the undocumented exported declarations of packages:
+
+    goda list -f "{{.ID}} {{.Stat.API.Undocumented}}/{{.Stat.API.Total}}" ./...
+
 Robert Martin's package metrics are available on nodes (populated by
 "goda metrics" and "goda list"):
 
@@ -275,11 +341,42 @@ When -types flag is used, structural coupling metrics are also available:
 
     SCa float64 // Packages whose types satisfy this package's interfaces (no import).
     SCe float64 // Packages whose interfaces are satisfied by this package's types (no import).
+    RefinedA float64 // Abstractness from the type checked scope, see "help metrics".
+    RefinedD float64 // Distance from the main sequence using RefinedA.
+    H        float64 // Relational cohesion: (references + 1) / types and funcs.
+    LCOM     float64 // Ratio of type and func pairs not referring to each other.
+    Components int   // Connected components of types and funcs.
+
+With -coverprofile the test coverage from "go test -coverprofile"
+is available as well:
+
+    Coverage          float64 // Percentage of covered statements.
+    Statements        int     // Statements in the profile, zero when missing.
+    CoveredStatements int
+
+Lakos's physical design metrics are available on nodes, "goda metrics
+-summary" prints their sum (CCD) and derived system metrics:
+
+    DependsOn int // Packages this package depends on transitively, including itself.
+
+Centrality in the import graph is available on nodes as well, it is
+computed by "list", "metrics" and "graph" when the format, sort key or
+condition refers to it:
+
+    PageRank     float64 // PageRank, rank flows from importers to imports.
+    Betweenness  float64 // Shortest paths between other packages through this package.
+    Depth        int     // Longest path to a package without imports.
+    ReverseDepth int     // Longest path from a package without importers.
+
+With -level module or -level dir=N, the nodes are groups of packages
+and the metrics count only imports crossing group boundaries. The grouped
+packages are available as .Members.
 
 Example:
 
     goda list -f "{{.ID}}\t{{printf \"%.2f\" .D}}" ./...
     goda metrics -types ./...
+    goda list -level module -f "{{.ID}}\t{{len .Members}}\t{{.Ca}}\t{{.Ce}}" ./...:all
 
 "goda cut" command additionally contains:
 